@@ -2,6 +2,7 @@ package ast
 
 import (
 	"bytes"
+	"math/big"
 	"monkey/token"
 	"strings"
 )
@@ -9,6 +10,7 @@ import (
 type Node interface {
 	TokenLiteral() string
 	String() string
+	Pos() token.Position // position of the first character belonging to the node
 }
 
 type Statement interface {
@@ -47,6 +49,16 @@ type IntegerLiteral struct {
 	Value int64
 }
 
+type BigIntegerLiteral struct {
+	Token token.Token
+	Value *big.Int
+}
+
+type FloatLiteral struct {
+	Token token.Token
+	Value float64
+}
+
 type PrefixExpression struct {
 	Token    token.Token
 	Operator string
@@ -60,6 +72,15 @@ type InfixExpression struct {
 	Right    Expression
 }
 
+// AssignExpression rebinds Target, an identifier or an index expression.
+// Operator is "=" or a compound operator like "+=".
+type AssignExpression struct {
+	Token    token.Token
+	Target   Expression
+	Operator string
+	Value    Expression
+}
+
 type Boolean struct {
 	Token token.Token
 	Value bool
@@ -101,25 +122,75 @@ type ArrayLiteral struct {
 
 type IndexExpression struct {
 	Token token.Token
-	Left Expression
+	Left  Expression
 	Index Expression
 }
 
+// SliceExpression is left[start:end]; Start and End are nil when omitted.
+type SliceExpression struct {
+	Token token.Token
+	Left  Expression
+	Start Expression
+	End   Expression
+}
+
 type ObjectLiteral struct {
 	Token token.Token
 	Pairs map[Expression]Expression
 }
 
+type WhileStatement struct {
+	Token     token.Token
+	Condition Expression
+	Body      *BlockStatement
+}
+
+// ForStatement is the C-style for loop, each of Init, Condition and Post may
+// be nil.
+type ForStatement struct {
+	Token     token.Token
+	Init      Statement
+	Condition Expression
+	Post      Expression
+	Body      *BlockStatement
+}
+
+// ForInStatement iterates over the elements of Iterable, binding each of them
+// to Variable.
+type ForInStatement struct {
+	Token    token.Token
+	Variable *Identifier
+	Iterable Expression
+	Body     *BlockStatement
+}
+
+type BreakStatement struct {
+	Token token.Token
+}
+
+type ContinueStatement struct {
+	Token token.Token
+}
+
+// BadStatement stands in for a statement that could not be parsed, it spans
+// from Token up to End.
+type BadStatement struct {
+	Token token.Token
+	End   token.Position
+}
+
 type Program struct {
 	Statements []Statement
 }
 
 func (i *Identifier) expressionNode()      {}
 func (i *Identifier) TokenLiteral() string { return i.Token.Literal }
+func (i *Identifier) Pos() token.Position  { return i.Token.Start }
 func (i *Identifier) String() string       { return i.Value }
 
 func (ls *LetStatement) statementNode()       {}
 func (ls *LetStatement) TokenLiteral() string { return ls.Token.Literal }
+func (ls *LetStatement) Pos() token.Position  { return ls.Token.Start }
 func (ls *LetStatement) String() string {
 	var out bytes.Buffer
 
@@ -138,6 +209,7 @@ func (ls *LetStatement) String() string {
 
 func (rs *ReturnStatement) statementNode()       {}
 func (rs *ReturnStatement) TokenLiteral() string { return rs.Token.Literal }
+func (rs *ReturnStatement) Pos() token.Position  { return rs.Token.Start }
 func (rs *ReturnStatement) String() string {
 	var out bytes.Buffer
 
@@ -154,6 +226,7 @@ func (rs *ReturnStatement) String() string {
 
 func (es *ExpressionStatement) statementNode()       {}
 func (es *ExpressionStatement) TokenLiteral() string { return es.Token.Literal }
+func (es *ExpressionStatement) Pos() token.Position  { return es.Token.Start }
 func (es *ExpressionStatement) String() string {
 	if es.Expression != nil {
 		return es.Expression.String()
@@ -163,10 +236,22 @@ func (es *ExpressionStatement) String() string {
 
 func (il *IntegerLiteral) expressionNode()      {}
 func (il *IntegerLiteral) TokenLiteral() string { return il.Token.Literal }
+func (il *IntegerLiteral) Pos() token.Position  { return il.Token.Start }
 func (il *IntegerLiteral) String() string       { return il.Token.Literal }
 
+func (bl *BigIntegerLiteral) expressionNode()      {}
+func (bl *BigIntegerLiteral) TokenLiteral() string { return bl.Token.Literal }
+func (bl *BigIntegerLiteral) Pos() token.Position  { return bl.Token.Start }
+func (bl *BigIntegerLiteral) String() string       { return bl.Token.Literal }
+
+func (fl *FloatLiteral) expressionNode()      {}
+func (fl *FloatLiteral) TokenLiteral() string { return fl.Token.Literal }
+func (fl *FloatLiteral) Pos() token.Position  { return fl.Token.Start }
+func (fl *FloatLiteral) String() string       { return fl.Token.Literal }
+
 func (pe *PrefixExpression) expressionNode()      {}
 func (pe *PrefixExpression) TokenLiteral() string { return pe.Token.Literal }
+func (pe *PrefixExpression) Pos() token.Position  { return pe.Token.Start }
 func (pe *PrefixExpression) String() string {
 	var out bytes.Buffer
 
@@ -180,6 +265,7 @@ func (pe *PrefixExpression) String() string {
 
 func (oe *InfixExpression) expressionNode()      {}
 func (oe *InfixExpression) TokenLiteral() string { return oe.Token.Literal }
+func (oe *InfixExpression) Pos() token.Position  { return oe.Left.Pos() }
 func (oe *InfixExpression) String() string {
 	var out bytes.Buffer
 
@@ -192,12 +278,29 @@ func (oe *InfixExpression) String() string {
 	return out.String()
 }
 
+func (ae *AssignExpression) expressionNode()      {}
+func (ae *AssignExpression) TokenLiteral() string { return ae.Token.Literal }
+func (ae *AssignExpression) Pos() token.Position  { return ae.Target.Pos() }
+func (ae *AssignExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("(")
+	out.WriteString(ae.Target.String())
+	out.WriteString(" " + ae.Operator + " ")
+	out.WriteString(ae.Value.String())
+	out.WriteString(")")
+
+	return out.String()
+}
+
 func (b *Boolean) expressionNode()      {}
 func (b *Boolean) TokenLiteral() string { return b.Token.Literal }
+func (b *Boolean) Pos() token.Position  { return b.Token.Start }
 func (b *Boolean) String() string       { return b.Token.Literal }
 
 func (ie *IfExpression) expressionNode()      {}
 func (ie *IfExpression) TokenLiteral() string { return ie.Token.Literal }
+func (ie *IfExpression) Pos() token.Position  { return ie.Token.Start }
 func (ie *IfExpression) String() string {
 	var out bytes.Buffer
 
@@ -216,6 +319,7 @@ func (ie *IfExpression) String() string {
 
 func (bs *BlockStatement) statementNode()       {}
 func (bs *BlockStatement) TokenLiteral() string { return bs.Token.Literal }
+func (bs *BlockStatement) Pos() token.Position  { return bs.Token.Start }
 func (bs *BlockStatement) String() string {
 	var out bytes.Buffer
 
@@ -228,6 +332,7 @@ func (bs *BlockStatement) String() string {
 
 func (fl *FunctionLiteral) expressionNode()      {}
 func (fl *FunctionLiteral) TokenLiteral() string { return fl.Token.Literal }
+func (fl *FunctionLiteral) Pos() token.Position  { return fl.Token.Start }
 func (fl *FunctionLiteral) String() string {
 	var out bytes.Buffer
 
@@ -247,6 +352,7 @@ func (fl *FunctionLiteral) String() string {
 
 func (ce *CallExpression) expressionNode()      {}
 func (ce *CallExpression) TokenLiteral() string { return ce.Token.Literal }
+func (ce *CallExpression) Pos() token.Position  { return ce.Function.Pos() }
 func (ce *CallExpression) String() string {
 	var out bytes.Buffer
 
@@ -265,10 +371,12 @@ func (ce *CallExpression) String() string {
 
 func (sl *StringLiteral) expressionNode()      {}
 func (sl *StringLiteral) TokenLiteral() string { return sl.Token.Literal }
+func (sl *StringLiteral) Pos() token.Position  { return sl.Token.Start }
 func (sl *StringLiteral) String() string       { return sl.Token.Literal }
 
 func (al *ArrayLiteral) expressionNode()      {}
 func (al *ArrayLiteral) TokenLiteral() string { return al.Token.Literal }
+func (al *ArrayLiteral) Pos() token.Position  { return al.Token.Start }
 func (al *ArrayLiteral) String() string {
 	var out bytes.Buffer
 
@@ -284,8 +392,9 @@ func (al *ArrayLiteral) String() string {
 	return out.String()
 }
 
-func (ide *IndexExpression) expressionNode() {}
-func (ide *IndexExpression) TokenLiteral() string { return ide.Token.Literal} 
+func (ide *IndexExpression) expressionNode()      {}
+func (ide *IndexExpression) TokenLiteral() string { return ide.Token.Literal }
+func (ide *IndexExpression) Pos() token.Position  { return ide.Left.Pos() }
 func (ide *IndexExpression) String() string {
 	var out bytes.Buffer
 
@@ -298,14 +407,36 @@ func (ide *IndexExpression) String() string {
 	return out.String()
 }
 
-func (ol *ObjectLiteral) expressionNode() {}
+func (se *SliceExpression) expressionNode()      {}
+func (se *SliceExpression) TokenLiteral() string { return se.Token.Literal }
+func (se *SliceExpression) Pos() token.Position  { return se.Left.Pos() }
+func (se *SliceExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("(")
+	out.WriteString(se.Left.String())
+	out.WriteString("[")
+	if se.Start != nil {
+		out.WriteString(se.Start.String())
+	}
+	out.WriteString(":")
+	if se.End != nil {
+		out.WriteString(se.End.String())
+	}
+	out.WriteString("])")
+
+	return out.String()
+}
+
+func (ol *ObjectLiteral) expressionNode()      {}
 func (ol *ObjectLiteral) TokenLiteral() string { return ol.Token.Literal }
+func (ol *ObjectLiteral) Pos() token.Position  { return ol.Token.Start }
 func (ol *ObjectLiteral) String() string {
 	var out bytes.Buffer
 
 	pairs := []string{}
 	for k, v := range ol.Pairs {
-		pairs = append(pairs, k.String() + ": " + v.String())
+		pairs = append(pairs, k.String()+": "+v.String())
 	}
 
 	out.WriteString("{")
@@ -315,6 +446,75 @@ func (ol *ObjectLiteral) String() string {
 	return out.String()
 }
 
+func (ws *WhileStatement) statementNode()       {}
+func (ws *WhileStatement) TokenLiteral() string { return ws.Token.Literal }
+func (ws *WhileStatement) Pos() token.Position  { return ws.Token.Start }
+func (ws *WhileStatement) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("while (")
+	out.WriteString(ws.Condition.String())
+	out.WriteString(") ")
+	out.WriteString(ws.Body.String())
+
+	return out.String()
+}
+
+func (fs *ForStatement) statementNode()       {}
+func (fs *ForStatement) TokenLiteral() string { return fs.Token.Literal }
+func (fs *ForStatement) Pos() token.Position  { return fs.Token.Start }
+func (fs *ForStatement) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("for (")
+	if fs.Init != nil {
+		out.WriteString(strings.TrimSuffix(fs.Init.String(), ";"))
+	}
+	out.WriteString("; ")
+	if fs.Condition != nil {
+		out.WriteString(fs.Condition.String())
+	}
+	out.WriteString("; ")
+	if fs.Post != nil {
+		out.WriteString(fs.Post.String())
+	}
+	out.WriteString(") ")
+	out.WriteString(fs.Body.String())
+
+	return out.String()
+}
+
+func (fis *ForInStatement) statementNode()       {}
+func (fis *ForInStatement) TokenLiteral() string { return fis.Token.Literal }
+func (fis *ForInStatement) Pos() token.Position  { return fis.Token.Start }
+func (fis *ForInStatement) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("for (")
+	out.WriteString(fis.Variable.String())
+	out.WriteString(" in ")
+	out.WriteString(fis.Iterable.String())
+	out.WriteString(") ")
+	out.WriteString(fis.Body.String())
+
+	return out.String()
+}
+
+func (bs *BreakStatement) statementNode()       {}
+func (bs *BreakStatement) TokenLiteral() string { return bs.Token.Literal }
+func (bs *BreakStatement) Pos() token.Position  { return bs.Token.Start }
+func (bs *BreakStatement) String() string       { return bs.Token.Literal + ";" }
+
+func (cs *ContinueStatement) statementNode()       {}
+func (cs *ContinueStatement) TokenLiteral() string { return cs.Token.Literal }
+func (cs *ContinueStatement) Pos() token.Position  { return cs.Token.Start }
+func (cs *ContinueStatement) String() string       { return cs.Token.Literal + ";" }
+
+func (bs *BadStatement) statementNode()       {}
+func (bs *BadStatement) TokenLiteral() string { return bs.Token.Literal }
+func (bs *BadStatement) Pos() token.Position  { return bs.Token.Start }
+func (bs *BadStatement) String() string       { return "<bad statement>" }
+
 func (p *Program) TokenLiteral() string {
 	if len(p.Statements) > 0 {
 		return p.Statements[0].TokenLiteral()
@@ -323,6 +523,13 @@ func (p *Program) TokenLiteral() string {
 	}
 }
 
+func (p *Program) Pos() token.Position {
+	if len(p.Statements) > 0 {
+		return p.Statements[0].Pos()
+	}
+	return token.Position{}
+}
+
 func (p *Program) String() string {
 	var out bytes.Buffer
 
@@ -3,126 +3,693 @@ package evaluator
 import (
 	"fmt"
 	"errors"
+	"io"
+	"math"
+	"math/big"
 	"monkey/object"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
 )
 
 var builtinMethods = map[string]*object.BuiltinMethod{
 	"parseInt": &object.BuiltinMethod{
-		Fn: func(args ...object.Object) object.Object {
-			return NULL
+		Fn: func(env *object.Environment, args ...object.Object) object.Object {
+			return parseInt.Fn(env, args...)
+		},
+	},
+	"parseFloat": &object.BuiltinMethod{
+		Fn: func(env *object.Environment, args ...object.Object) object.Object {
+			return parseFloat.Fn(env, args...)
+		},
+	},
+	"bigint": &object.BuiltinMethod{
+		Fn: func(env *object.Environment, args ...object.Object) object.Object {
+			return bigint.Fn(env, args...)
+		},
+	},
+	"puts": &object.BuiltinMethod{
+		Fn: func(env *object.Environment, args ...object.Object) object.Object {
+			return puts.Fn(env, args...)
+		},
+	},
+	"print": &object.BuiltinMethod{
+		Fn: func(env *object.Environment, args ...object.Object) object.Object {
+			return printFn.Fn(env, args...)
+		},
+	},
+	"printf": &object.BuiltinMethod{
+		Fn: func(env *object.Environment, args ...object.Object) object.Object {
+			return printf.Fn(env, args...)
+		},
+	},
+	"format": &object.BuiltinMethod{
+		Fn: func(env *object.Environment, args ...object.Object) object.Object {
+			return formatFn.Fn(env, args...)
+		},
+	},
+	"split": &object.BuiltinMethod{
+		Fn: func(env *object.Environment, args ...object.Object) object.Object {
+			return split.Fn(env, args...)
+		},
+	},
+	"join": &object.BuiltinMethod{
+		Fn: func(env *object.Environment, args ...object.Object) object.Object {
+			return join.Fn(env, args...)
+		},
+	},
+	"trim": &object.BuiltinMethod{
+		Fn: func(env *object.Environment, args ...object.Object) object.Object {
+			return trim.Fn(env, args...)
+		},
+	},
+	"upper": &object.BuiltinMethod{
+		Fn: func(env *object.Environment, args ...object.Object) object.Object {
+			return upper.Fn(env, args...)
+		},
+	},
+	"lower": &object.BuiltinMethod{
+		Fn: func(env *object.Environment, args ...object.Object) object.Object {
+			return lower.Fn(env, args...)
+		},
+	},
+	"replace": &object.BuiltinMethod{
+		Fn: func(env *object.Environment, args ...object.Object) object.Object {
+			return replace.Fn(env, args...)
+		},
+	},
+	"contains": &object.BuiltinMethod{
+		Fn: func(env *object.Environment, args ...object.Object) object.Object {
+			return contains.Fn(env, args...)
+		},
+	},
+	"startsWith": &object.BuiltinMethod{
+		Fn: func(env *object.Environment, args ...object.Object) object.Object {
+			return startsWith.Fn(env, args...)
+		},
+	},
+	"endsWith": &object.BuiltinMethod{
+		Fn: func(env *object.Environment, args ...object.Object) object.Object {
+			return endsWith.Fn(env, args...)
+		},
+	},
+	"indexOf": &object.BuiltinMethod{
+		Fn: func(env *object.Environment, args ...object.Object) object.Object {
+			return indexOf.Fn(env, args...)
+		},
+	},
+	"repeat": &object.BuiltinMethod{
+		Fn: func(env *object.Environment, args ...object.Object) object.Object {
+			return repeat.Fn(env, args...)
+		},
+	},
+	"padLeft": &object.BuiltinMethod{
+		Fn: func(env *object.Environment, args ...object.Object) object.Object {
+			return padLeft.Fn(env, args...)
+		},
+	},
+	"padRight": &object.BuiltinMethod{
+		Fn: func(env *object.Environment, args ...object.Object) object.Object {
+			return padRight.Fn(env, args...)
+		},
+	},
+	"abs": &object.BuiltinMethod{
+		Fn: func(env *object.Environment, args ...object.Object) object.Object {
+			return abs.Fn(env, args...)
+		},
+	},
+	"min": &object.BuiltinMethod{
+		Fn: func(env *object.Environment, args ...object.Object) object.Object {
+			return minFn.Fn(env, args...)
+		},
+	},
+	"max": &object.BuiltinMethod{
+		Fn: func(env *object.Environment, args ...object.Object) object.Object {
+			return maxFn.Fn(env, args...)
+		},
+	},
+	"pow": &object.BuiltinMethod{
+		Fn: func(env *object.Environment, args ...object.Object) object.Object {
+			return pow.Fn(env, args...)
+		},
+	},
+	"sqrt": &object.BuiltinMethod{
+		Fn: func(env *object.Environment, args ...object.Object) object.Object {
+			return sqrt.Fn(env, args...)
+		},
+	},
+	"floor": &object.BuiltinMethod{
+		Fn: func(env *object.Environment, args ...object.Object) object.Object {
+			return floor.Fn(env, args...)
+		},
+	},
+	"ceil": &object.BuiltinMethod{
+		Fn: func(env *object.Environment, args ...object.Object) object.Object {
+			return ceil.Fn(env, args...)
+		},
+	},
+	"round": &object.BuiltinMethod{
+		Fn: func(env *object.Environment, args ...object.Object) object.Object {
+			return round.Fn(env, args...)
+		},
+	},
+	"clamp": &object.BuiltinMethod{
+		Fn: func(env *object.Environment, args ...object.Object) object.Object {
+			return clamp.Fn(env, args...)
+		},
+	},
+	"random": &object.BuiltinMethod{
+		Fn: func(env *object.Environment, args ...object.Object) object.Object {
+			return random.Fn(env, args...)
+		},
+	},
+	"randomInt": &object.BuiltinMethod{
+		Fn: func(env *object.Environment, args ...object.Object) object.Object {
+			return randomInt.Fn(env, args...)
+		},
+	},
+	"sort": &object.BuiltinMethod{
+		Fn: func(env *object.Environment, args ...object.Object) object.Object {
+			return sortFn.Fn(env, args...)
+		},
+	},
+	"range": &object.BuiltinMethod{
+		Fn: func(env *object.Environment, args ...object.Object) object.Object {
+			return rangeFn.Fn(env, args...)
+		},
+	},
+	"keys": &object.BuiltinMethod{
+		Fn: func(env *object.Environment, args ...object.Object) object.Object {
+			return keys.Fn(env, args...)
+		},
+	},
+	"values": &object.BuiltinMethod{
+		Fn: func(env *object.Environment, args ...object.Object) object.Object {
+			return values.Fn(env, args...)
+		},
+	},
+	"entries": &object.BuiltinMethod{
+		Fn: func(env *object.Environment, args ...object.Object) object.Object {
+			return entries.Fn(env, args...)
+		},
+	},
+	"has": &object.BuiltinMethod{
+		Fn: func(env *object.Environment, args ...object.Object) object.Object {
+			return has.Fn(env, args...)
+		},
+	},
+	"delete": &object.BuiltinMethod{
+		Fn: func(env *object.Environment, args ...object.Object) object.Object {
+			return deleteFn.Fn(env, args...)
+		},
+	},
+	"merge": &object.BuiltinMethod{
+		Fn: func(env *object.Environment, args ...object.Object) object.Object {
+			return merge.Fn(env, args...)
+		},
+	},
+	"zip": &object.BuiltinMethod{
+		Fn: func(env *object.Environment, args ...object.Object) object.Object {
+			return zip.Fn(env, args...)
+		},
+	},
+	"reverse": &object.BuiltinMethod{
+		Fn: func(env *object.Environment, args ...object.Object) object.Object {
+			return reverse.Fn(env, args...)
+		},
+	},
+	"flatten": &object.BuiltinMethod{
+		Fn: func(env *object.Environment, args ...object.Object) object.Object {
+			return flatten.Fn(env, args...)
+		},
+	},
+	"unique": &object.BuiltinMethod{
+		Fn: func(env *object.Environment, args ...object.Object) object.Object {
+			return unique.Fn(env, args...)
+		},
+	},
+	"find": &object.BuiltinMethod{
+		Fn: func(env *object.Environment, args ...object.Object) object.Object {
+			return find.Fn(env, args...)
+		},
+	},
+	"findIndex": &object.BuiltinMethod{
+		Fn: func(env *object.Environment, args ...object.Object) object.Object {
+			return findIndex.Fn(env, args...)
+		},
+	},
+	"some": &object.BuiltinMethod{
+		Fn: func(env *object.Environment, args ...object.Object) object.Object {
+			return some.Fn(env, args...)
+		},
+	},
+	"every": &object.BuiltinMethod{
+		Fn: func(env *object.Environment, args ...object.Object) object.Object {
+			return every.Fn(env, args...)
+		},
+	},
+	"each": &object.BuiltinMethod{
+		Fn: func(env *object.Environment, args ...object.Object) object.Object {
+			return each.Fn(env, args...)
+		},
+	},
+	"isNaN": &object.BuiltinMethod{
+		Fn: func(env *object.Environment, args ...object.Object) object.Object {
+			return isNaN.Fn(env, args...)
+		},
+	},
+	"int": &object.BuiltinMethod{
+		Fn: func(env *object.Environment, args ...object.Object) object.Object {
+			return toInt.Fn(env, args...)
+		},
+	},
+	"str": &object.BuiltinMethod{
+		Fn: func(env *object.Environment, args ...object.Object) object.Object {
+			return toStr.Fn(env, args...)
+		},
+	},
+	"bool": &object.BuiltinMethod{
+		Fn: func(env *object.Environment, args ...object.Object) object.Object {
+			return toBool.Fn(env, args...)
+		},
+	},
+	"type": &object.BuiltinMethod{
+		Fn: func(env *object.Environment, args ...object.Object) object.Object {
+			return typeOf.Fn(env, args...)
 		},
 	},
 	"len": &object.BuiltinMethod{
-		Fn: func(args ...object.Object) object.Object {
-			return length.Fn(args...)
+		Fn: func(env *object.Environment, args ...object.Object) object.Object {
+			return length.Fn(env, args...)
 		},
 	},
 	"slice": &object.BuiltinMethod{
-		Fn: func(args ...object.Object) object.Object {
-			return slice.Fn(args...)
+		Fn: func(env *object.Environment, args ...object.Object) object.Object {
+			return slice.Fn(env, args...)
 		},
 	},
 	"head": &object.BuiltinMethod {
-		Fn: func(args ...object.Object) object.Object {
-			return head.Fn(args...)			
+		Fn: func(env *object.Environment, args ...object.Object) object.Object {
+			return head.Fn(env, args...)			
 		},
 	},
 	"tail": &object.BuiltinMethod {
-		Fn: func(args ...object.Object) object.Object {
-			return tail.Fn(args...)			
+		Fn: func(env *object.Environment, args ...object.Object) object.Object {
+			return tail.Fn(env, args...)			
 		},
 	},
 	"push": &object.BuiltinMethod {
-		Fn: func(args ...object.Object) object.Object {
-			return push.Fn(args...)			
+		Fn: func(env *object.Environment, args ...object.Object) object.Object {
+			return push.Fn(env, args...)			
 		},
 	},
 	"map": &object.BuiltinMethod {
-		Fn: func(args ...object.Object) object.Object {
-			return mapFn.Fn(args...)			
+		Fn: func(env *object.Environment, args ...object.Object) object.Object {
+			return mapFn.Fn(env, args...)			
 		},
 	},
 	"reduce": &object.BuiltinMethod {
-		Fn: func(args ...object.Object) object.Object {
-			return reduce.Fn(args...)			
+		Fn: func(env *object.Environment, args ...object.Object) object.Object {
+			return reduce.Fn(env, args...)			
+		},
+	},
+	"reduceRight": &object.BuiltinMethod{
+		Fn: func(env *object.Environment, args ...object.Object) object.Object {
+			return reduceRight.Fn(env, args...)
 		},
 	},
 	"filter": &object.BuiltinMethod {
-		Fn: func(args ...object.Object) object.Object {
-			return filter.Fn(args...)			
+		Fn: func(env *object.Environment, args ...object.Object) object.Object {
+			return filter.Fn(env, args...)			
 		},
 	},
 }
 
 var puts = &object.BuiltinMethod{
-	Fn: func(args ...object.Object) object.Object {
+	Fn: func(env *object.Environment, args ...object.Object) object.Object {
+		output := evaluationOf(env).output()
 		for _, arg := range args {
-			fmt.Println(arg.Inspect())
+			fmt.Fprintln(output, arg.Inspect())
 		}
 		return NULL
 	},
 }
 
-var slice = &object.BuiltinMethod{
-	Fn: func(args ...object.Object) object.Object {
-		if len(args) < 2 || len(args) > 3 {
-			return newError(`slice(arr, begin, *end) parameters are:
-				arr: array on which slice is performed,
-				begin: zero-based index at which to begin extraction,
-				end: (optional) Zero-based index before which to end extraction. slice extracts up to but not including end
-			`)
+var printFn = &object.BuiltinMethod{
+	Fn: func(env *object.Environment, args ...object.Object) object.Object {
+		output := evaluationOf(env).output()
+		for i, arg := range args {
+			if i > 0 {
+				io.WriteString(output, " ")
+			}
+			io.WriteString(output, arg.Inspect())
 		}
-		arr, ok := args[0].(*object.Array)
+		return NULL
+	},
+}
+
+var printf = &object.BuiltinMethod{
+	Fn: func(env *object.Environment, args ...object.Object) object.Object {
+		if len(args) == 0 {
+			return newError("printf() needs a format string")
+		}
+		format, ok := args[0].(*object.String)
+		if !ok {
+			return newError("printf() format must be a string, got=%s", args[0].Type())
+		}
+
+		out, err := formatObjects("printf", format.Value, args[1:])
+		if err != nil {
+			return err
+		}
+		io.WriteString(evaluationOf(env).output(), out)
+		return NULL
+	},
+}
+
+var formatFn = &object.BuiltinMethod{
+	Fn: func(env *object.Environment, args ...object.Object) object.Object {
+		if len(args) == 0 {
+			return newError("format() needs a format string")
+		}
+		format, ok := args[0].(*object.String)
 		if !ok {
-			return newError("slice() only supports arrays, got=%s", args[0].Type())
+			return newError("format() format must be a string, got=%s", args[0].Type())
 		}
 
-		output := &object.Array{}
-		var beginIdx, endIdx int64
-		var okBegin, okEnd error
+		out, err := formatObjects("format", format.Value, args[1:])
+		if err != nil {
+			return err
+		}
+		return &object.String{Value: out}
+	},
+}
 
+var parseInt = &object.BuiltinMethod{
+	Fn: func(env *object.Environment, args ...object.Object) object.Object {
+		if len(args) < 1 || len(args) > 2 {
+			return newError(`parseInt(value, *base) parameters are:
+				value: string (or number) to parse,
+				base: optional radix between 2 and 36, guessed from a 0x prefix when omitted,
+			`)
+		}
+
+		base := 0
 		if len(args) == 2 {
-			beginIdx, okBegin = extractSliceIndex(args[1])
-			if okBegin != nil {
-				return newError("parameters 'begin' and 'end' of slice() must be integers!")					
+			radix, ok := args[1].(*object.Integer)
+			if !ok {
+				return newError("parseInt() base must be an integer, got=%s", args[1].Type())
 			}
-		} else {
-			beginIdx, okBegin = extractSliceIndex(args[1])
-			endIdx, okEnd = extractSliceIndex(args[2])
-			if okBegin != nil || okEnd != nil {
-				return newError("parameters 'begin' and 'end' of slice() must be integers!")
+			if radix.Value < 2 || radix.Value > 36 {
+				return newError("parseInt() base must be between 2 and 36, got=%d", radix.Value)
+			}
+			base = int(radix.Value)
+		}
+
+		switch arg := args[0].(type) {
+		case *object.Integer, *object.BigInteger:
+			return arg
+		case *object.Float:
+			if math.IsNaN(arg.Value) || math.IsInf(arg.Value, 0) {
+				return &object.Float{Value: math.NaN()}
 			}
+			return integerFromBig(bigFromFloat(math.Trunc(arg.Value)))
+		case *object.String:
+			return parseIntPrefix(arg.Value, base)
+		default:
+			return newError("parseInt() accepts only strings / numbers, got=%s", args[0].Type())
+		}
+	},
+}
+
+// parseIntPrefix parses like JavaScript: leading whitespace and a sign are
+// skipped, then the longest run of digits valid in base is read. Without any
+// digit the result is NaN.
+func parseIntPrefix(s string, base int) object.Object {
+	s = strings.TrimLeftFunc(s, unicode.IsSpace)
+
+	sign := ""
+	if strings.HasPrefix(s, "-") || strings.HasPrefix(s, "+") {
+		sign, s = s[:1], s[1:]
+	}
+
+	if (base == 0 || base == 16) && len(s) > 1 && s[0] == '0' && (s[1] == 'x' || s[1] == 'X') {
+		s, base = s[2:], 16
+	}
+	if base == 0 {
+		base = 10
+	}
+
+	end := 0
+	for end < len(s) && digitValue(s[end]) < base {
+		end++
+	}
+	if end == 0 {
+		return &object.Float{Value: math.NaN()}
+	}
+
+	value, _ := new(big.Int).SetString(sign+s[:end], base)
+	return integerFromBig(value)
+}
+
+func digitValue(ch byte) int {
+	switch {
+	case '0' <= ch && ch <= '9':
+		return int(ch - '0')
+	case 'a' <= ch && ch <= 'z':
+		return int(ch-'a') + 10
+	case 'A' <= ch && ch <= 'Z':
+		return int(ch-'A') + 10
+	}
+	return 36
+}
+
+// parseIntegerString parses s in the notation of integer literals: a sign,
+// _ separators and a 0x, 0o or 0b prefix are accepted. Without a prefix s is
+// decimal, leading zeros included, rather than legacy octal.
+func parseIntegerString(s string) (*big.Int, bool) {
+	sign, digits := "", s
+	if strings.HasPrefix(digits, "-") || strings.HasPrefix(digits, "+") {
+		sign, digits = digits[:1], digits[1:]
+	}
+	if len(digits) > 1 && digits[0] == '0' && !strings.ContainsRune("xXoObB", rune(digits[1])) {
+		digits = strings.TrimLeft(digits, "0_")
+		if digits == "" {
+			digits = "0"
+		}
+	}
+	return new(big.Int).SetString(sign+digits, 0)
+}
+
+// integerFromBig returns an Integer when the value fits in an int64 and a
+// BigInteger otherwise.
+func integerFromBig(value *big.Int) object.Object {
+	if value.IsInt64() {
+		return &object.Integer{Value: value.Int64()}
+	}
+	return &object.BigInteger{Value: value}
+}
+
+func bigFromFloat(value float64) *big.Int {
+	result, _ := big.NewFloat(value).Int(nil)
+	return result
+}
+
+var toInt = &object.BuiltinMethod{
+	Fn: func(env *object.Environment, args ...object.Object) object.Object {
+		if len(args) != 1 {
+			return newError("int() accepts single parameter, got=%d", len(args))
+		}
+
+		switch arg := args[0].(type) {
+		case *object.Integer, *object.BigInteger:
+			return arg
+		case *object.Float:
+			if math.IsNaN(arg.Value) || math.IsInf(arg.Value, 0) {
+				return newError("int() cannot convert %s", arg.Inspect())
+			}
+			return integerFromBig(bigFromFloat(math.Trunc(arg.Value)))
+		case *object.Boolean:
+			if arg.Value {
+				return &object.Integer{Value: 1}
+			}
+			return &object.Integer{Value: 0}
+		case *object.String:
+			// the whole string has to be an integer, in any literal notation
+			value, ok := parseIntegerString(strings.TrimSpace(arg.Value))
+			if !ok {
+				return newError("int() cannot parse %q", arg.Value)
+			}
+			return integerFromBig(value)
+		default:
+			return newError("int() accepts only strings / numbers / booleans, got=%s", args[0].Type())
+		}
+	},
+}
+
+var toStr = &object.BuiltinMethod{
+	Fn: func(env *object.Environment, args ...object.Object) object.Object {
+		if len(args) != 1 {
+			return newError("str() accepts single parameter, got=%d", len(args))
 		}
 
-		for idx := beginIdx; idx < endIdx && idx < int64(len(arr.Elements)); idx++ {
-			output.Elements = append(output.Elements, arr.Elements[idx])
+		if str, ok := args[0].(*object.String); ok {
+			return str
+		}
+		return &object.String{Value: args[0].Inspect()}
+	},
+}
+
+var toBool = &object.BuiltinMethod{
+	Fn: func(env *object.Environment, args ...object.Object) object.Object {
+		if len(args) != 1 {
+			return newError("bool() accepts single parameter, got=%d", len(args))
 		}
 
-		return output
+		return nativeBoolToBooleanObject(isTruthy(args[0]))
+	},
+}
+
+var typeOf = &object.BuiltinMethod{
+	Fn: func(env *object.Environment, args ...object.Object) object.Object {
+		if len(args) != 1 {
+			return newError("type() accepts single parameter, got=%d", len(args))
+		}
+
+		return &object.String{Value: string(args[0].Type())}
+	},
+}
+
+var floatPrefix = regexp.MustCompile(`^[+-]?(\d+\.?\d*|\.\d+)([eE][+-]?\d+)?`)
+
+var parseFloat = &object.BuiltinMethod{
+	Fn: func(env *object.Environment, args ...object.Object) object.Object {
+		if len(args) != 1 {
+			return newError("parseFloat() accepts single parameter, got=%d", len(args))
+		}
+
+		switch arg := args[0].(type) {
+		case *object.Float:
+			return arg
+		case *object.Integer, *object.BigInteger:
+			return &object.Float{Value: toFloat(arg)}
+		case *object.String:
+			// like JavaScript, parse the longest numeric prefix and yield NaN
+			// when there is none
+			prefix := floatPrefix.FindString(strings.TrimSpace(arg.Value))
+			value, err := strconv.ParseFloat(prefix, 64)
+			if err != nil && !errors.Is(err, strconv.ErrRange) {
+				return &object.Float{Value: math.NaN()}
+			}
+			return &object.Float{Value: value}
+		default:
+			return newError("parseFloat() accepts only strings / numbers, got=%s", args[0].Type())
+		}
+	},
+}
+
+var bigint = &object.BuiltinMethod{
+	Fn: func(env *object.Environment, args ...object.Object) object.Object {
+		if len(args) != 1 {
+			return newError("bigint() accepts single parameter, got=%d", len(args))
+		}
+
+		switch arg := args[0].(type) {
+		case *object.BigInteger:
+			return arg
+		case *object.Integer:
+			return &object.BigInteger{Value: big.NewInt(arg.Value)}
+		case *object.Float:
+			if math.IsInf(arg.Value, 0) || arg.Value != math.Trunc(arg.Value) {
+				return newError("bigint() cannot convert %s, not an integer", arg.Inspect())
+			}
+			value, _ := big.NewFloat(arg.Value).Int(nil)
+			return &object.BigInteger{Value: value}
+		case *object.String:
+			// accepts the same notations as literals: 0x, 0o, 0b, _ and n
+			s := strings.TrimSuffix(strings.TrimSpace(arg.Value), "n")
+			value, ok := parseIntegerString(s)
+			if !ok {
+				return newError("bigint() cannot parse %q", arg.Value)
+			}
+			return &object.BigInteger{Value: value}
+		default:
+			return newError("bigint() accepts only strings / numbers, got=%s", args[0].Type())
+		}
+	},
+}
+
+var isNaN = &object.BuiltinMethod{
+	Fn: func(env *object.Environment, args ...object.Object) object.Object {
+		if len(args) != 1 {
+			return newError("isNaN() accepts single parameter, got=%d", len(args))
+		}
+
+		switch arg := args[0].(type) {
+		case *object.Float:
+			return nativeBoolToBooleanObject(math.IsNaN(arg.Value))
+		case *object.Integer, *object.BigInteger:
+			return FALSE
+		default:
+			return newError("isNaN() accepts only numbers, got=%s", args[0].Type())
+		}
+	},
+}
+
+var slice = &object.BuiltinMethod{
+	Fn: func(env *object.Environment, args ...object.Object) object.Object {
+		if len(args) < 2 || len(args) > 3 {
+			return newError(`slice(arr, begin, *end) parameters are:
+				arr: array or string on which slice is performed,
+				begin: zero-based index at which to begin extraction, negative counts from the end,
+				end: (optional) Zero-based index before which to end extraction, negative counts from the end. slice extracts up to but not including end, defaults to the length
+			`)
+		}
+		if args[0].Type() != object.ARRAY_OBJ && args[0].Type() != object.STRING_OBJ {
+			return newError("slice() only supports arrays and strings, got=%s", args[0].Type())
+		}
+
+		var endIdx int64 = math.MaxInt64
+		var okEnd error
+
+		beginIdx, okBegin := extractSliceIndex(args[1])
+		if len(args) == 3 {
+			endIdx, okEnd = extractSliceIndex(args[2])
+		}
+		if okBegin != nil || okEnd != nil {
+			return newError("parameters 'begin' and 'end' of slice() must be integers!")
+		}
+
+		return sliceObject(args[0], beginIdx, endIdx)
 	},
 }
 
 var mapFn = &object.BuiltinMethod {
-	Fn: func(args ...object.Object) object.Object {
+	Fn: func(env *object.Environment, args ...object.Object) object.Object {
 		if len(args) != 2 {
 			return newError(`map(arr, fn) parameters are:
 				arr: array on which map is performed,
-				fn: zero-based index at which to begin extraction,
+				fn: function called with each element, its results form the new array,
 			`)
 		}
 		arr, ok := args[0].(*object.Array)
 		if !ok {
 			return newError("map() only supports arrays, got=%s", args[0].Type())
 		}
-		fn, ok := args[1].(*object.Function)
-		if !ok {
-			return newError("fn must be function, got=%s", args[1].Type())
+		fn := args[1]
+		if err := checkCallable("map", fn); err != nil {
+			return err
 		}
 
 		output := make([]object.Object, len(arr.Elements), len(arr.Elements))
 
 		for i, mapArg := range arr.Elements {
-			output[i] = applyFunction(fn, []object.Object{mapArg})
+			output[i] = applyFunction(env, fn, []object.Object{mapArg})
+			if isError(output[i]) {
+				return output[i]
+			}
 		}
 
 		return &object.Array{Elements: output}
@@ -130,76 +697,109 @@ var mapFn = &object.BuiltinMethod {
 }
 
 var reduce = &object.BuiltinMethod {
-	Fn: func(args ...object.Object) object.Object {
-		if len(args) < 2 || len(args) > 3 {
-			return newError(`reduce(arr, fn, initial) parameters are:
-				arr: array on which slice is performed,
-				fn: zero-based index at which to call reducer,
+	Fn: func(env *object.Environment, args ...object.Object) object.Object {
+		return reduceArray(env, "reduce", args, false)
+	},
+}
+
+var reduceRight = &object.BuiltinMethod{
+	Fn: func(env *object.Environment, args ...object.Object) object.Object {
+		return reduceArray(env, "reduceRight", args, true)
+	},
+}
+
+// reduceArray folds the array into an accumulator of any type, from the last
+// element to the first if fromRight is set. The callback gets the
+// accumulator and an element, and also the element's index and the array if
+// it declares parameters for them.
+func reduceArray(env *object.Environment, name string, args []object.Object, fromRight bool) object.Object {
+	if len(args) < 2 || len(args) > 3 {
+		return newError(`%s(arr, fn, initial) parameters are:
+				arr: array on which %s is performed,
+				fn: reducer called with the accumulator, an element and optionally its index and arr,
 				initial: Value to use as the first argument to the first call of the callback. If no initial value is supplied, the first element in the array will be used.
-				 				 Calling reduce() on an empty array without an initial value is an error,
-			`)
+				 				 Calling %s() on an empty array without an initial value is an error,
+			`, name, name, name)
+	}
+	arr, ok := args[0].(*object.Array)
+	if !ok {
+		return newError("%s() only supports arrays, got=%s", name, args[0].Type())
+	}
+	fn := args[1]
+	if err := checkCallable(name, fn); err != nil {
+		return err
+	}
+
+	indexes := make([]int, len(arr.Elements))
+	for i := range indexes {
+		if fromRight {
+			indexes[i] = len(indexes) - 1 - i
+		} else {
+			indexes[i] = i
 		}
-		arr, ok := args[0].(*object.Array)
-		if !ok {
-			return newError("reduce() only supports arrays, got=%s", args[0].Type())
+	}
+
+	var acc object.Object
+	if len(args) == 2 {
+		if len(indexes) == 0 {
+			return newError("If %s() hasn't received initial value, provided array musn't be empty", name)
 		}
-		fn, ok := args[1].(*object.Function)
-		if !ok {
-			return newError("fn must be functon, got=%s", args[1].Type())
+		acc = arr.Elements[indexes[0]]
+		indexes = indexes[1:]
+	} else {
+		acc = args[2]
+	}
+
+	for _, i := range indexes {
+		acc = callWithOptionalArgs(env, fn, []object.Object{acc, arr.Elements[i]},
+			&object.Integer{Value: int64(i)}, arr)
+		if isError(acc) {
+			return acc
 		}
-		
-		var initialVal object.Object
+	}
+	return acc
+}
 
-		if len(args) == 2 {
-			if (len(arr.Elements) == 0) {
-				return newError("If reduce() hasn't received initial value, provided array musn't be empty", args[0].Type())
-			}
-			initialVal = arr.Elements[0]
-		} else {
-			initialVal = args[2]
-		}
-
-		switch initialVal.Type() {
-		case object.INTEGER_OBJ:
-			integer := initialVal.(*object.Integer)
-			if len(args) == 2 {
-				for i := 1; i < len(arr.Elements) ; i++ {
-					integer = applyFunction(fn, []object.Object{integer, arr.Elements[i]}).(*object.Integer)
-				}
-			} else {
-				for _, mapArg := range arr.Elements {
-					integer = applyFunction(fn, []object.Object{integer, mapArg}).(*object.Integer)
-				}
-			}
-			return integer
-		default:
-			return newError("Unsupported type %s of initial value", initialVal.Type())
+// callWithOptionalArgs calls fn with args, followed by as many of optional
+// as a user function declares parameters for. Builtins only get args.
+func callWithOptionalArgs(env *object.Environment, fn object.Object, args []object.Object, optional ...object.Object) object.Object {
+	if function, ok := fn.(*object.Function); ok {
+		extra := len(function.Parameters) - len(args)
+		if extra > len(optional) {
+			extra = len(optional)
 		}
-	},
+		if extra > 0 {
+			args = append(args, optional[:extra]...)
+		}
+	}
+	return applyFunction(env, fn, args)
 }
 
 var filter = &object.BuiltinMethod {
-	Fn: func(args ...object.Object) object.Object {
+	Fn: func(env *object.Environment, args ...object.Object) object.Object {
 		if len(args) != 2 {
 			return newError(`filter(arr, fn) parameters are:
 				arr: array on which filtering is performed,
-				fn: zero-based index at which to begin extraction,
+				fn: function called with each element, the ones it returns a truthy value for are kept,
 			`)
 		}
 		arr, ok := args[0].(*object.Array)
 		if !ok {
-			return newError("map() only supports arrays, got=%s", args[0].Type())
+			return newError("filter() only supports arrays, got=%s", args[0].Type())
 		}
-		fn, ok := args[1].(*object.Function)
-		if !ok {
-			return newError("fn must be function, got=%s", args[1].Type())
+		fn := args[1]
+		if err := checkCallable("filter", fn); err != nil {
+			return err
 		}
 
 		output := []object.Object{}
 
 		for _, mapArg := range arr.Elements {
-			result := applyFunction(fn, []object.Object{mapArg}).(*object.Boolean)
-			if result.Value {
+			result := applyFunction(env, fn, []object.Object{mapArg})
+			if isError(result) {
+				return result
+			}
+			if isTruthy(result) {
 				output = append(output, mapArg)
 			}
 		}
@@ -209,7 +809,7 @@ var filter = &object.BuiltinMethod {
 }
 
 var length = &object.BuiltinMethod {
-	Fn: func(args ...object.Object) object.Object {
+	Fn: func(env *object.Environment, args ...object.Object) object.Object {
 		if len(args) != 1 {
 			return newError("len() accepts single parameter, got=%d", len(args))
 		}
@@ -218,7 +818,7 @@ var length = &object.BuiltinMethod {
 		case *object.Array:
 			return &object.Integer{Value: int64(len(arg.Elements))}
 		case *object.String:
-			return &object.Integer{Value: int64(len(arg.Value)) }
+			return &object.Integer{Value: int64(utf8.RuneCountInString(arg.Value))}
 		default:
 			return newError("len() accepts only	strings / arrays, got=%s", args[0].Type())
 		}
@@ -226,7 +826,7 @@ var length = &object.BuiltinMethod {
 }
 
 var head = &object.BuiltinMethod {
-	Fn: func(args ...object.Object) object.Object {
+	Fn: func(env *object.Environment, args ...object.Object) object.Object {
 		if len(args) != 1 {
 			return newError(`head(arr) parameters are:
 				arr: array on which head is performed,
@@ -236,12 +836,12 @@ var head = &object.BuiltinMethod {
 		if !ok {
 			return newError("head() only supports arrays, got=%s", args[0].Type())
 		}
-		return slice.Fn(arr, &object.Integer{Value:0}, &object.Integer{Value:1})
+		return slice.Fn(env, arr, &object.Integer{Value:0}, &object.Integer{Value:1})
 	},
 }
 
 var tail = &object.BuiltinMethod {
-	Fn: func(args ...object.Object) object.Object {
+	Fn: func(env *object.Environment, args ...object.Object) object.Object {
 		if len(args) != 1 {
 			return newError(`tail(arr) parameters are:
 				arr: array on which tail is performed,
@@ -251,12 +851,12 @@ var tail = &object.BuiltinMethod {
 		if !ok {
 			return newError("tail() only supports arrays, got=%s", args[0].Type())
 		}
-		return slice.Fn(args[0], &object.Integer{Value:1}, &object.Integer{Value:int64(len(arr.Elements))})
+		return slice.Fn(env, args[0], &object.Integer{Value:1}, &object.Integer{Value:int64(len(arr.Elements))})
 	},
 }
 
 var push = &object.BuiltinMethod {
-	Fn: func(args ...object.Object) object.Object {
+	Fn: func(env *object.Environment, args ...object.Object) object.Object {
 		if len(args) < 2 {
 			return newError(`push(arr, ...elements) parameters are:
 				arr: array on which slice is performed,
@@ -0,0 +1,496 @@
+package evaluator
+
+import (
+	"monkey/object"
+	"sort"
+)
+
+// maxRangeLength bounds the number of elements range() creates.
+const maxRangeLength = 1 << 24
+
+var sortFn = &object.BuiltinMethod{
+	Fn: func(env *object.Environment, args ...object.Object) object.Object {
+		if len(args) < 1 || len(args) > 2 {
+			return newError(`sort(arr, *fn) parameters are:
+				arr: array of numbers or strings to sort, it is left unchanged,
+				fn: (optional) comparator called with two elements, returning a negative number, zero or a positive number as the first one sorts before, with or after the second,
+			`)
+		}
+		arr, ok := args[0].(*object.Array)
+		if !ok {
+			return newError("sort() only supports arrays, got=%s", args[0].Type())
+		}
+		if len(args) == 2 {
+			if err := checkCallable("sort", args[1]); err != nil {
+				return err
+			}
+		}
+
+		elements := make([]object.Object, len(arr.Elements))
+		copy(elements, arr.Elements)
+
+		var err object.Object
+		sort.SliceStable(elements, func(i, j int) bool {
+			if err != nil {
+				return false
+			}
+			var order int
+			if len(args) == 2 {
+				order, err = callComparator(env, args[1], elements[i], elements[j])
+			} else {
+				order, err = compareObjects(elements[i], elements[j])
+			}
+			return order < 0
+		})
+		if err != nil {
+			return err
+		}
+
+		return &object.Array{Elements: elements}
+	},
+}
+
+// compareObjects is the default order of sort(): numbers by value, strings
+// lexicographically.
+func compareObjects(a, b object.Object) (int, object.Object) {
+	switch {
+	case isNumber(a) && isNumber(b):
+		return compareNumbers(a, b), nil
+	case a.Type() == object.STRING_OBJ && b.Type() == object.STRING_OBJ:
+		av, bv := a.(*object.String).Value, b.(*object.String).Value
+		switch {
+		case av < bv:
+			return -1, nil
+		case av > bv:
+			return 1, nil
+		}
+		return 0, nil
+	default:
+		return 0, newError("sort() cannot compare %s with %s", a.Type(), b.Type())
+	}
+}
+
+func callComparator(env *object.Environment, fn, a, b object.Object) (int, object.Object) {
+	result := applyFunction(env, fn, []object.Object{a, b})
+	if isError(result) {
+		return 0, result
+	}
+	if !isNumber(result) {
+		return 0, newError("sort() comparator must return a number, got=%s", result.Type())
+	}
+	return compareNumbers(result, &object.Integer{Value: 0}), nil
+}
+
+var rangeFn = &object.BuiltinMethod{
+	Fn: func(env *object.Environment, args ...object.Object) object.Object {
+		if len(args) < 1 || len(args) > 3 {
+			return newError(`range(*start, end, *step) parameters are:
+				start: (optional) first integer, 0 by default,
+				end: integer at which to stop, it is not included,
+				step: (optional) difference between consecutive integers, 1 by default, negative to count down,
+			`)
+		}
+		values := make([]int64, len(args))
+		for i, arg := range args {
+			integer, ok := arg.(*object.Integer)
+			if !ok {
+				return newError("range() only supports integers, got=%s", arg.Type())
+			}
+			values[i] = integer.Value
+		}
+
+		start, end, step := int64(0), values[0], int64(1)
+		if len(values) > 1 {
+			start, end = values[0], values[1]
+		}
+		if len(values) > 2 {
+			step = values[2]
+		}
+		if step == 0 {
+			return newError("range() step must not be zero")
+		}
+
+		// The distance between start and end and the magnitude of step may
+		// not fit in an int64, but they always fit in an uint64.
+		var count uint64
+		if step > 0 && start < end {
+			count = (uint64(end)-uint64(start)-1)/uint64(step) + 1
+		} else if step < 0 && start > end {
+			count = (uint64(start)-uint64(end)-1)/(uint64(-(step+1))+1) + 1
+		}
+		if count > maxRangeLength {
+			return newError("range() would create more than %d elements", maxRangeLength)
+		}
+
+		// start + i*step may wrap around midway, but every element lies
+		// between start and end, so the wrapped result is still exact.
+		elements := make([]object.Object, count)
+		for i := range elements {
+			elements[i] = &object.Integer{Value: start + int64(i)*step}
+		}
+		return &object.Array{Elements: elements}
+	},
+}
+
+var keys = &object.BuiltinMethod{
+	Fn: func(env *object.Environment, args ...object.Object) object.Object {
+		obj, err := singleObjectLiteral("keys", args)
+		if err != nil {
+			return err
+		}
+		pairs := obj.SortedPairs()
+		elements := make([]object.Object, len(pairs))
+		for i, pair := range pairs {
+			elements[i] = pair.Key
+		}
+		return &object.Array{Elements: elements}
+	},
+}
+
+var values = &object.BuiltinMethod{
+	Fn: func(env *object.Environment, args ...object.Object) object.Object {
+		obj, err := singleObjectLiteral("values", args)
+		if err != nil {
+			return err
+		}
+		pairs := obj.SortedPairs()
+		elements := make([]object.Object, len(pairs))
+		for i, pair := range pairs {
+			elements[i] = pair.Value
+		}
+		return &object.Array{Elements: elements}
+	},
+}
+
+var entries = &object.BuiltinMethod{
+	Fn: func(env *object.Environment, args ...object.Object) object.Object {
+		obj, err := singleObjectLiteral("entries", args)
+		if err != nil {
+			return err
+		}
+		pairs := obj.SortedPairs()
+		elements := make([]object.Object, len(pairs))
+		for i, pair := range pairs {
+			elements[i] = &object.Array{Elements: []object.Object{pair.Key, pair.Value}}
+		}
+		return &object.Array{Elements: elements}
+	},
+}
+
+var has = &object.BuiltinMethod{
+	Fn: func(env *object.Environment, args ...object.Object) object.Object {
+		if len(args) != 2 {
+			return newError(`has(obj, key) parameters are:
+				obj: object literal to look in,
+				key: key to look for,
+			`)
+		}
+		obj, ok := args[0].(*object.ObjectLiteral)
+		if !ok {
+			return newError("has() only supports object literals, got=%s", args[0].Type())
+		}
+		key, ok := args[1].(object.Hashable)
+		if !ok {
+			return newError("Can't hash object of type %s", args[1].Type())
+		}
+		_, ok = obj.Pairs[key.HashKey()]
+		return nativeBoolToBooleanObject(ok)
+	},
+}
+
+var deleteFn = &object.BuiltinMethod{
+	Fn: func(env *object.Environment, args ...object.Object) object.Object {
+		if len(args) != 2 {
+			return newError(`delete(obj, key) parameters are:
+				obj: object literal to copy without key, it is left unchanged,
+				key: key to leave out,
+			`)
+		}
+		obj, ok := args[0].(*object.ObjectLiteral)
+		if !ok {
+			return newError("delete() only supports object literals, got=%s", args[0].Type())
+		}
+		key, ok := args[1].(object.Hashable)
+		if !ok {
+			return newError("Can't hash object of type %s", args[1].Type())
+		}
+
+		pairs := make(map[object.HashKey]object.HashPair, len(obj.Pairs))
+		for hashKey, pair := range obj.Pairs {
+			pairs[hashKey] = pair
+		}
+		delete(pairs, key.HashKey())
+		return &object.ObjectLiteral{Pairs: pairs}
+	},
+}
+
+var merge = &object.BuiltinMethod{
+	Fn: func(env *object.Environment, args ...object.Object) object.Object {
+		if len(args) == 0 {
+			return newError(`merge(...objs) parameters are:
+				objs: object literals to combine into a new one, later ones win on equal keys,
+			`)
+		}
+
+		pairs := make(map[object.HashKey]object.HashPair)
+		for _, arg := range args {
+			obj, ok := arg.(*object.ObjectLiteral)
+			if !ok {
+				return newError("merge() only supports object literals, got=%s", arg.Type())
+			}
+			for hashKey, pair := range obj.Pairs {
+				pairs[hashKey] = pair
+			}
+		}
+		return &object.ObjectLiteral{Pairs: pairs}
+	},
+}
+
+var zip = &object.BuiltinMethod{
+	Fn: func(env *object.Environment, args ...object.Object) object.Object {
+		if len(args) == 0 {
+			return newError(`zip(...arrs) parameters are:
+				arrs: arrays whose elements at the same index are grouped, the shortest one sets the length,
+			`)
+		}
+
+		arrs := make([]*object.Array, len(args))
+		length := -1
+		for i, arg := range args {
+			arr, ok := arg.(*object.Array)
+			if !ok {
+				return newError("zip() only supports arrays, got=%s", arg.Type())
+			}
+			arrs[i] = arr
+			if length < 0 || len(arr.Elements) < length {
+				length = len(arr.Elements)
+			}
+		}
+
+		elements := make([]object.Object, length)
+		for i := range elements {
+			group := make([]object.Object, len(arrs))
+			for j, arr := range arrs {
+				group[j] = arr.Elements[i]
+			}
+			elements[i] = &object.Array{Elements: group}
+		}
+		return &object.Array{Elements: elements}
+	},
+}
+
+var reverse = &object.BuiltinMethod{
+	Fn: func(env *object.Environment, args ...object.Object) object.Object {
+		if len(args) != 1 {
+			return newError("reverse() accepts single parameter, got=%d", len(args))
+		}
+
+		switch arg := args[0].(type) {
+		case *object.Array:
+			n := len(arg.Elements)
+			elements := make([]object.Object, n)
+			for i, el := range arg.Elements {
+				elements[n-1-i] = el
+			}
+			return &object.Array{Elements: elements}
+		case *object.String:
+			runes := []rune(arg.Value)
+			for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+				runes[i], runes[j] = runes[j], runes[i]
+			}
+			return &object.String{Value: string(runes)}
+		default:
+			return newError("reverse() only supports arrays / strings, got=%s", args[0].Type())
+		}
+	},
+}
+
+var flatten = &object.BuiltinMethod{
+	Fn: func(env *object.Environment, args ...object.Object) object.Object {
+		if len(args) < 1 || len(args) > 2 {
+			return newError(`flatten(arr, *depth) parameters are:
+				arr: array whose nested arrays are spliced into it,
+				depth: (optional) how many levels of nesting to remove, 1 by default,
+			`)
+		}
+		arr, ok := args[0].(*object.Array)
+		if !ok {
+			return newError("flatten() only supports arrays, got=%s", args[0].Type())
+		}
+		depth := int64(1)
+		if len(args) == 2 {
+			d, ok := args[1].(*object.Integer)
+			if !ok || d.Value < 0 {
+				return newError("flatten() depth must be a non-negative integer, got=%s", args[1].Inspect())
+			}
+			depth = d.Value
+		}
+
+		flattening := map[*object.Array]bool{arr: true}
+		return &object.Array{Elements: flattenElements(nil, arr.Elements, depth, flattening)}
+	},
+}
+
+// flattenElements splices nested arrays into out. flattening holds the arrays
+// being flattened on the way down; an array met again contains itself, so it
+// is kept as an element instead of being flattened forever.
+func flattenElements(
+	out, elements []object.Object,
+	depth int64,
+	flattening map[*object.Array]bool,
+) []object.Object {
+	for _, el := range elements {
+		nested, ok := el.(*object.Array)
+		if !ok || depth == 0 || flattening[nested] {
+			out = append(out, el)
+			continue
+		}
+
+		flattening[nested] = true
+		out = flattenElements(out, nested.Elements, depth-1, flattening)
+		delete(flattening, nested)
+	}
+	return out
+}
+
+var unique = &object.BuiltinMethod{
+	Fn: func(env *object.Environment, args ...object.Object) object.Object {
+		if len(args) != 1 {
+			return newError("unique() accepts single parameter, got=%d", len(args))
+		}
+		arr, ok := args[0].(*object.Array)
+		if !ok {
+			return newError("unique() only supports arrays, got=%s", args[0].Type())
+		}
+
+		elements := []object.Object{}
+	outer:
+		for _, el := range arr.Elements {
+			for _, seen := range elements {
+				if object.Equal(el, seen) {
+					continue outer
+				}
+			}
+			elements = append(elements, el)
+		}
+		return &object.Array{Elements: elements}
+	},
+}
+
+var find = &object.BuiltinMethod{
+	Fn: func(env *object.Environment, args ...object.Object) object.Object {
+		idx, err := findMatch(env, "find", args)
+		if err != nil {
+			return err
+		}
+		if idx < 0 {
+			return NULL
+		}
+		return args[0].(*object.Array).Elements[idx]
+	},
+}
+
+var findIndex = &object.BuiltinMethod{
+	Fn: func(env *object.Environment, args ...object.Object) object.Object {
+		idx, err := findMatch(env, "findIndex", args)
+		if err != nil {
+			return err
+		}
+		return &object.Integer{Value: int64(idx)}
+	},
+}
+
+var some = &object.BuiltinMethod{
+	Fn: func(env *object.Environment, args ...object.Object) object.Object {
+		idx, err := findMatch(env, "some", args)
+		if err != nil {
+			return err
+		}
+		return nativeBoolToBooleanObject(idx >= 0)
+	},
+}
+
+var every = &object.BuiltinMethod{
+	Fn: func(env *object.Environment, args ...object.Object) object.Object {
+		if err := checkArrayAndCallable("every", args); err != nil {
+			return err
+		}
+		for _, el := range args[0].(*object.Array).Elements {
+			result := applyFunction(env, args[1], []object.Object{el})
+			if isError(result) {
+				return result
+			}
+			if !isTruthy(result) {
+				return FALSE
+			}
+		}
+		return TRUE
+	},
+}
+
+// findMatch returns the index of the first element for which the callback
+// is truthy, or -1.
+func findMatch(env *object.Environment, name string, args []object.Object) (int, object.Object) {
+	if err := checkArrayAndCallable(name, args); err != nil {
+		return 0, err
+	}
+	for i, el := range args[0].(*object.Array).Elements {
+		result := applyFunction(env, args[1], []object.Object{el})
+		if isError(result) {
+			return 0, result
+		}
+		if isTruthy(result) {
+			return i, nil
+		}
+	}
+	return -1, nil
+}
+
+var each = &object.BuiltinMethod{
+	Fn: func(env *object.Environment, args ...object.Object) object.Object {
+		if err := checkArrayAndCallable("each", args); err != nil {
+			return err
+		}
+		for i, el := range args[0].(*object.Array).Elements {
+			result := callWithOptionalArgs(env, args[1], []object.Object{el}, &object.Integer{Value: int64(i)})
+			if isError(result) {
+				return result
+			}
+		}
+		return NULL
+	},
+}
+
+func checkArrayAndCallable(name string, args []object.Object) object.Object {
+	if len(args) != 2 {
+		return newError(`%s(arr, fn) parameters are:
+				arr: array to go through,
+				fn: function called with each element,
+			`, name)
+	}
+	if _, ok := args[0].(*object.Array); !ok {
+		return newError("%s() only supports arrays, got=%s", name, args[0].Type())
+	}
+	return checkCallable(name, args[1])
+}
+
+// checkCallable accepts whatever applyFunction is able to call, builtins
+// included, so that higher-order builtins take any of them.
+func checkCallable(name string, fn object.Object) object.Object {
+	switch fn.(type) {
+	case *object.Function, *object.BuiltinMethod:
+		return nil
+	}
+	return newError("%s() fn must be a function, got=%s", name, fn.Type())
+}
+
+func singleObjectLiteral(name string, args []object.Object) (*object.ObjectLiteral, object.Object) {
+	if len(args) != 1 {
+		return nil, newError("%s() accepts single parameter, got=%d", name, len(args))
+	}
+	obj, ok := args[0].(*object.ObjectLiteral)
+	if !ok {
+		return nil, newError("%s() only supports object literals, got=%s", name, args[0].Type())
+	}
+	return obj, nil
+}
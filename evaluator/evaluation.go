@@ -0,0 +1,75 @@
+package evaluator
+
+import (
+	"io"
+	"math/rand"
+	"monkey/object"
+	"os"
+	"time"
+)
+
+// Config holds the settings of an evaluation. Hosts embedding the
+// interpreter pass it to NewEnvironment, the zero value gives the defaults.
+type Config struct {
+	// Overflow is the policy applied to +, -, *, / and ** on integers.
+	Overflow OverflowPolicy
+
+	// Output receives everything scripts print, os.Stdout if nil.
+	Output io.Writer
+
+	// Random is the generator behind random() and randomInt(). Hosts that
+	// need reproducible runs, tests among them, pass a seeded one:
+	//
+	//	evaluator.Config{Random: rand.New(rand.NewSource(42))}
+	//
+	// If nil, the evaluation seeds its own from the clock.
+	Random *rand.Rand
+}
+
+// evaluation is the state of one run of the interpreter. It hangs off the
+// outermost environment, so concurrent evaluations in separate environments
+// do not share it.
+type evaluation struct {
+	config Config
+
+	// callStack holds a frame for every function call in progress,
+	// innermost last. Errors take a snapshot of it on their way out of the
+	// innermost call.
+	callStack []object.StackFrame
+}
+
+// NewEnvironment returns the outermost environment of an evaluation that
+// runs with config.
+func NewEnvironment(config Config) *object.Environment {
+	env := object.NewEnvironment()
+	env.SetHost(&evaluation{config: config})
+	return env
+}
+
+// output is where puts, print and printf write to.
+func (ev *evaluation) output() io.Writer {
+	if ev.config.Output == nil {
+		return os.Stdout
+	}
+	return ev.config.Output
+}
+
+// random returns the generator of the evaluation, creating it on first use
+// when the config has none.
+func (ev *evaluation) random() *rand.Rand {
+	if ev.config.Random == nil {
+		ev.config.Random = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	return ev.config.Random
+}
+
+// evaluationOf returns the state of the evaluation env belongs to, starting
+// one with the default config when env was not made by NewEnvironment.
+func evaluationOf(env *object.Environment) *evaluation {
+	if ev, ok := env.Host().(*evaluation); ok {
+		return ev
+	}
+	ev := &evaluation{}
+	env.SetHost(ev)
+	return ev
+}
@@ -2,31 +2,46 @@ package evaluator
 
 import (
 	"fmt"
+	"math"
+	"math/big"
 	"monkey/ast"
 	"monkey/object"
+	"strings"
 )
 
 var (
-	NULL  = &object.Null{}
-	TRUE  = &object.Boolean{Value: true}
-	FALSE = &object.Boolean{Value: false}
+	NULL     = &object.Null{}
+	TRUE     = &object.Boolean{Value: true}
+	FALSE    = &object.Boolean{Value: false}
+	BREAK    = &object.Break{}
+	CONTINUE = &object.Continue{}
 )
 
-var applyFunction func(fn object.Object, args []object.Object) object.Object
+// applyFunction calls fn on behalf of a builtin, such as the callback of
+// map(). The call is recorded on the stack at the builtin's call site.
+var applyFunction func(env *object.Environment, fn object.Object, args []object.Object) object.Object
 
 func init() {
-	applyFunction = func(fn object.Object, args []object.Object) object.Object {
-			switch fn := fn.(type) {
-			case *object.Function:
-				extendedEnv := extendFunctionEnv(fn, args)
-				evaluated := Eval(fn.Body, extendedEnv)
-				return unwrapReturnValue(evaluated)
-			case *object.BuiltinMethod:
-				return fn.Fn(args...)
-			default:
-				return newError("not a function, got=%s", fn.Type())
-			}
+	applyFunction = func(env *object.Environment, fn object.Object, args []object.Object) object.Object {
+		return callFunction(env, functionName(fn, nil), fn, args, currentPosition(env))
+	}
+}
+
+func invokeFunction(env *object.Environment, fn object.Object, args []object.Object) object.Object {
+	switch fn := fn.(type) {
+	case *object.Function:
+		if len(args) < len(fn.Parameters) {
+			return newError("wrong number of arguments: want=%d, got=%d",
+				len(fn.Parameters), len(args))
 		}
+		extendedEnv := extendFunctionEnv(fn, args)
+		evaluated := Eval(fn.Body, extendedEnv)
+		return unwrapReturnValue(evaluated)
+	case *object.BuiltinMethod:
+		return fn.Fn(env, args...)
+	default:
+		return newError("not a function, got=%s", fn.Type())
+	}
 }
 
 func Eval(node ast.Node, env *object.Environment) object.Object {
@@ -37,40 +52,64 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		return Eval(node.Expression, env)
 	case *ast.IntegerLiteral:
 		return &object.Integer{Value: node.Value}
+	case *ast.BigIntegerLiteral:
+		return &object.BigInteger{Value: node.Value}
+	case *ast.FloatLiteral:
+		return &object.Float{Value: node.Value}
 	case *ast.Boolean:
 		return nativeBoolToBooleanObject(node.Value)
 	case *ast.PrefixExpression:
 		right := Eval(node.Right, env)
-		if isError(right) {
+		if isAbrupt(right) {
 			return right
 		}
-		return evalPrefixExpression(node.Operator, right)
+		return evalPrefixExpression(node.Operator, right, evaluationOf(env).config.Overflow)
 	case *ast.InfixExpression:
+		if node.Operator == "&&" || node.Operator == "||" {
+			return evalLogicalExpression(node, env)
+		}
 		left := Eval(node.Left, env)
-		if isError(left) {
+		if isAbrupt(left) {
 			return left
 		}
 		right := Eval(node.Right, env)
-		if isError(right) {
+		if isAbrupt(right) {
 			return right
 		}
-		return evalInfixExpression(node.Operator, left, right)
+		return evalInfixExpression(node.Operator, left, right, evaluationOf(env).config.Overflow)
 	case *ast.BlockStatement:
 		return evalBlockStatement(node, env)
 	case *ast.IfExpression:
 		return evalIfExpression(node, env)
 	case *ast.ReturnStatement:
 		val := Eval(node.ReturnValue, env)
-		if isError(val) {
+		if isAbrupt(val) {
 			return val
 		}
 		return &object.ReturnValue{Value: val}
+	case *ast.WhileStatement:
+		return evalWhileStatement(node, env)
+	case *ast.ForStatement:
+		return evalForStatement(node, env)
+	case *ast.ForInStatement:
+		return evalForInStatement(node, env)
+	case *ast.BreakStatement:
+		return BREAK
+	case *ast.ContinueStatement:
+		return CONTINUE
 	case *ast.LetStatement:
 		val := Eval(node.Value, env)
-		if isError(val) {
+		if isAbrupt(val) {
 			return val
 		}
+		if fn, ok := val.(*object.Function); ok && fn.Name == "" {
+			if _, isLiteral := node.Value.(*ast.FunctionLiteral); isLiteral {
+				fn.Name = node.Name.Value
+			}
+		}
 		env.Set(node.Name.Value, val)
+	case *ast.AssignExpression:
+		return evalAssignExpression(node, env)
 	case *ast.Identifier:
 		return evalIdentifier(node, env)
 	case *ast.FunctionLiteral:
@@ -81,34 +120,38 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		}
 	case *ast.CallExpression:
 		function := Eval(node.Function, env)
-		if isError(function) {
+		if isAbrupt(function) {
 			return function
 		}
 		args := evalExpressions(node.Arguments, env)
-		if len(args) == 1 && isError(args[0]) {
+		if len(args) == 1 && isAbrupt(args[0]) {
 			return args[0]
 		}
-		return applyFunction(function, args)
+		return callFunction(env, functionName(function, node.Function), function, args, node.Pos())
 	case *ast.StringLiteral:
 		return &object.String{Value: node.Value}
 	case *ast.ArrayLiteral:
 		elements := evalExpressions(node.Elements, env)
-		if len(elements) == 1 && isError(elements[0]) {
+		if len(elements) == 1 && isAbrupt(elements[0]) {
 			return elements[0]
 		}
 		return &object.Array{Elements: elements}
 	case *ast.IndexExpression:
 		left := Eval(node.Left, env)
-		if isError(left) {
+		if isAbrupt(left) {
 			return left
 		}
 		idx := Eval(node.Index, env)
-		if isError(idx) {
+		if isAbrupt(idx) {
 			return idx
 		}
 		return evalIndexExpression(left, idx)
+	case *ast.SliceExpression:
+		return evalSliceExpression(node, env)
 	case *ast.ObjectLiteral:
 		return evalObjectLiteral(node, env)
+	case *ast.BadStatement:
+		return newError("cannot evaluate statement with syntax errors at %s", node.Pos())
 	}
 	return nil
 }
@@ -120,12 +163,14 @@ func nativeBoolToBooleanObject(input bool) *object.Boolean {
 	return FALSE
 }
 
-func evalPrefixExpression(operator string, right object.Object) object.Object {
+func evalPrefixExpression(operator string, right object.Object, policy OverflowPolicy) object.Object {
 	switch operator {
 	case "!":
 		return evalBangOperatorExpression(right)
 	case "-":
-		return evalMinusPrefixOperatorExpression(right)
+		return evalMinusPrefixOperatorExpression(right, policy)
+	case "~":
+		return evalBitwiseNotOperatorExpression(right)
 	default:
 		return newError("unknow operator: %s%s", operator, right.Type())
 	}
@@ -144,37 +189,84 @@ func evalBangOperatorExpression(right object.Object) object.Object {
 	}
 }
 
-func evalMinusPrefixOperatorExpression(right object.Object) object.Object {
+func evalMinusPrefixOperatorExpression(right object.Object, policy OverflowPolicy) object.Object {
 	// TODO: in future, also handle func obj, handle
 	switch right.Type() {
 	case object.INTEGER_OBJ:
 		value := right.(*object.Integer).Value
+		if value == math.MinInt64 {
+			return integerArithmetic("-", 0, value, policy)
+		}
 		return &object.Integer{Value: -value}
+	case object.BIG_INTEGER_OBJ:
+		value := right.(*object.BigInteger).Value
+		return &object.BigInteger{Value: new(big.Int).Neg(value)}
+	case object.FLOAT_OBJ:
+		value := right.(*object.Float).Value
+		return &object.Float{Value: -value}
 	default:
 		return NULL
 	}
 
 }
 
+func evalBitwiseNotOperatorExpression(right object.Object) object.Object {
+	switch right := right.(type) {
+	case *object.Integer:
+		return &object.Integer{Value: ^right.Value}
+	case *object.BigInteger:
+		return &object.BigInteger{Value: new(big.Int).Not(right.Value)}
+	default:
+		return newError("unknown operator: ~%s", right.Type())
+	}
+}
+
 func evalInfixExpression(
 	operator string,
 	left, right object.Object,
+	policy OverflowPolicy,
 ) object.Object {
 	switch {
+	case operator == "==":
+		return nativeBoolToBooleanObject(object.Equal(left, right))
+	case operator == "!=":
+		return nativeBoolToBooleanObject(!object.Equal(left, right))
+	case operator == "in":
+		return evalInExpression(left, right)
 	case left.Type() == object.STRING_OBJ && right.Type() == object.STRING_OBJ:
 		return evalStringInfixExpression(operator, left, right)
+	case operator == "*" && left.Type() == object.STRING_OBJ && right.Type() == object.INTEGER_OBJ:
+		return evalStringRepetition(left.(*object.String), right.(*object.Integer))
+	case operator == "*" && left.Type() == object.INTEGER_OBJ && right.Type() == object.STRING_OBJ:
+		return evalStringRepetition(right.(*object.String), left.(*object.Integer))
 	case left.Type() == object.INTEGER_OBJ && right.Type() == object.INTEGER_OBJ:
-		return evalIntegerInfixExpression(operator, left, right)
-	case operator == "==":
-		return nativeBoolToBooleanObject(left == right)
-	case operator == "!=":
-		return nativeBoolToBooleanObject(left != right)
+		return evalIntegerInfixExpression(operator, left, right, policy)
+	case isInteger(left) && isInteger(right):
+		return evalBigIntegerInfixExpression(operator, left, right)
+	case isNumber(left) && isNumber(right):
+		return evalFloatInfixExpression(operator, left, right)
 	default:
 		return newError("unknown operator: %s %s %s",
 			left.Type(), operator, right.Type())
 	}
 }
 
+// evalLogicalExpression evaluates the right operand only when the left one
+// does not already decide the result. Like in JavaScript, the result is the
+// deciding operand itself rather than a boolean.
+func evalLogicalExpression(node *ast.InfixExpression, env *object.Environment) object.Object {
+	left := Eval(node.Left, env)
+	if isAbrupt(left) {
+		return left
+	}
+
+	if isTruthy(left) == (node.Operator == "||") {
+		return left
+	}
+
+	return Eval(node.Right, env)
+}
+
 func evalStringInfixExpression(
 	operator string,
 	left, right object.Object,
@@ -183,48 +275,176 @@ func evalStringInfixExpression(
 	leftVal := left.(*object.String).Value
 	rightVal := right.(*object.String).Value
 
-	// TODO: coercion, comparsion
+	// TODO: coercion
 	switch operator {
 	case "+":
 		return &object.String{Value: leftVal + rightVal}
+	case "<":
+		return nativeBoolToBooleanObject(leftVal < rightVal)
+	case ">":
+		return nativeBoolToBooleanObject(leftVal > rightVal)
+	case "<=":
+		return nativeBoolToBooleanObject(leftVal <= rightVal)
+	case ">=":
+		return nativeBoolToBooleanObject(leftVal >= rightVal)
+	default:
+		return newError("unknown operator: %s %s %s",
+			left.Type(), operator, right.Type())
+	}
+}
+
+// maxRepeatLength bounds the length in bytes of a string built by "ab" * n.
+const maxRepeatLength = 1 << 28
+
+func evalStringRepetition(str *object.String, count *object.Integer) object.Object {
+	if count.Value < 0 {
+		return newError("negative repeat count: %d", count.Value)
+	}
+	if len(str.Value) > 0 && count.Value > int64(maxRepeatLength/len(str.Value)) {
+		return newError("repeated string too long: %d * %d bytes", count.Value, len(str.Value))
+	}
+	return &object.String{Value: strings.Repeat(str.Value, int(count.Value))}
+}
+
+// evalInExpression tests for a substring, an array element equal to left or
+// an object literal key.
+func evalInExpression(left, right object.Object) object.Object {
+	switch right := right.(type) {
+	case *object.String:
+		sub, ok := left.(*object.String)
+		if !ok {
+			return newError("unknown operator: %s in %s", left.Type(), right.Type())
+		}
+		return nativeBoolToBooleanObject(strings.Contains(right.Value, sub.Value))
+	case *object.Array:
+		for _, el := range right.Elements {
+			if object.Equal(left, el) {
+				return TRUE
+			}
+		}
+		return FALSE
+	case *object.ObjectLiteral:
+		key, ok := left.(object.Hashable)
+		if !ok {
+			return newError("Can't hash object of type %s", left.Type())
+		}
+		_, ok = right.Pairs[key.HashKey()]
+		return nativeBoolToBooleanObject(ok)
 	default:
-		return newError("Coercion not yet supported")
+		return newError("unknown operator: %s in %s", left.Type(), right.Type())
 	}
 }
 
 func evalIntegerInfixExpression(
 	operator string,
 	left, right object.Object,
+	policy OverflowPolicy,
 ) object.Object {
 	leftVal := left.(*object.Integer).Value
 	rightVal := right.(*object.Integer).Value
 
+	switch operator {
+	case "+", "-", "*":
+		return integerArithmetic(operator, leftVal, rightVal, policy)
+	case "/":
+		if rightVal == 0 {
+			return newError("division by zero")
+		}
+		return integerArithmetic(operator, leftVal, rightVal, policy)
+	case "%":
+		if rightVal == 0 {
+			return newError("modulo by zero")
+		}
+		return &object.Integer{Value: leftVal % rightVal}
+	case "**":
+		return integerPower(leftVal, rightVal, policy)
+	case "&":
+		return &object.Integer{Value: leftVal & rightVal}
+	case "|":
+		return &object.Integer{Value: leftVal | rightVal}
+	case "^":
+		return &object.Integer{Value: leftVal ^ rightVal}
+	case "<<", ">>":
+		// counts of 64 and above shift every bit out, leaving 0 (or -1 for
+		// >> of a negative number)
+		if rightVal < 0 {
+			return newError("negative shift count: %d", rightVal)
+		}
+		if operator == "<<" {
+			return &object.Integer{Value: leftVal << uint64(rightVal)}
+		}
+		return &object.Integer{Value: leftVal >> uint64(rightVal)}
+	case "<":
+		return nativeBoolToBooleanObject(leftVal < rightVal)
+	case ">":
+		return nativeBoolToBooleanObject(leftVal > rightVal)
+	case "<=":
+		return nativeBoolToBooleanObject(leftVal <= rightVal)
+	case ">=":
+		return nativeBoolToBooleanObject(leftVal >= rightVal)
+	default:
+		return newError("unknown operator: %s %s %s",
+			left.Type(), operator, right.Type())
+	}
+}
+
+// evalFloatInfixExpression handles float operands as well as mixed
+// integer/float operands; integers are promoted to float before the operation.
+func evalFloatInfixExpression(
+	operator string,
+	left, right object.Object,
+) object.Object {
+	leftVal := toFloat(left)
+	rightVal := toFloat(right)
+
 	switch operator {
 	case "+":
-		return &object.Integer{Value: leftVal + rightVal}
+		return &object.Float{Value: leftVal + rightVal}
 	case "-":
-		return &object.Integer{Value: leftVal - rightVal}
+		return &object.Float{Value: leftVal - rightVal}
 	case "*":
-		return &object.Integer{Value: leftVal * rightVal}
+		return &object.Float{Value: leftVal * rightVal}
 	case "/":
-		return &object.Integer{Value: leftVal / rightVal}
+		return &object.Float{Value: leftVal / rightVal}
+	case "%":
+		return &object.Float{Value: math.Mod(leftVal, rightVal)}
+	case "**":
+		return &object.Float{Value: math.Pow(leftVal, rightVal)}
 	case "<":
 		return nativeBoolToBooleanObject(leftVal < rightVal)
 	case ">":
 		return nativeBoolToBooleanObject(leftVal > rightVal)
-	case "==":
-		return nativeBoolToBooleanObject(leftVal == rightVal)
-	case "!=":
-		return nativeBoolToBooleanObject(leftVal != rightVal)
+	case "<=":
+		return nativeBoolToBooleanObject(leftVal <= rightVal)
+	case ">=":
+		return nativeBoolToBooleanObject(leftVal >= rightVal)
 	default:
-		return newError("unknown operator: %s %s %S",
+		return newError("unknown operator: %s %s %s",
 			left.Type(), operator, right.Type())
 	}
 }
 
+func isNumber(obj object.Object) bool {
+	return isInteger(obj) || obj.Type() == object.FLOAT_OBJ
+}
+
+func toFloat(obj object.Object) float64 {
+	switch obj := obj.(type) {
+	case *object.Integer:
+		return float64(obj.Value)
+	case *object.BigInteger:
+		value, _ := new(big.Float).SetInt(obj.Value).Float64()
+		return value
+	case *object.Float:
+		return obj.Value
+	default:
+		return math.NaN()
+	}
+}
+
 func evalIfExpression(ie *ast.IfExpression, env *object.Environment) object.Object {
 	condition := Eval(ie.Condition, env)
-	if isError(condition) {
+	if isAbrupt(condition) {
 		return condition
 	}
 	if isTruthy(condition) {
@@ -255,14 +475,113 @@ func evalBlockStatement(block *ast.BlockStatement, env *object.Environment) obje
 	for _, statement := range block.Statements {
 		result = Eval(statement, env)
 
-		if result != nil &&
-			(result.Type() == object.RETURN_VALUE_OBJ || result.Type() == object.ERROR_OBJ) {
-			return result
+		if result != nil {
+			rt := result.Type()
+			if rt == object.RETURN_VALUE_OBJ || rt == object.ERROR_OBJ ||
+				rt == object.BREAK_OBJ || rt == object.CONTINUE_OBJ {
+				return result
+			}
 		}
 	}
 	return result
 }
 
+// loopControl inspects the result of a loop body. It reports whether the
+// loop has to stop and, if so, what the loop statement evaluates to.
+func loopControl(result object.Object) (object.Object, bool) {
+	switch result.(type) {
+	case *object.Break:
+		return NULL, true
+	case *object.ReturnValue, *object.Error:
+		return result, true
+	}
+	return nil, false
+}
+
+func evalWhileStatement(ws *ast.WhileStatement, env *object.Environment) object.Object {
+	for {
+		condition := Eval(ws.Condition, env)
+		if isAbrupt(condition) {
+			return condition
+		}
+		if !isTruthy(condition) {
+			return NULL
+		}
+
+		if result, stop := loopControl(Eval(ws.Body, env)); stop {
+			return result
+		}
+	}
+}
+
+func evalForStatement(fs *ast.ForStatement, env *object.Environment) object.Object {
+	loopEnv := object.NewEnclosedEnvironment(env)
+
+	if fs.Init != nil {
+		if init := Eval(fs.Init, loopEnv); isAbrupt(init) {
+			return init
+		}
+	}
+
+	for {
+		if fs.Condition != nil {
+			condition := Eval(fs.Condition, loopEnv)
+			if isAbrupt(condition) {
+				return condition
+			}
+			if !isTruthy(condition) {
+				return NULL
+			}
+		}
+
+		if result, stop := loopControl(Eval(fs.Body, loopEnv)); stop {
+			return result
+		}
+
+		if fs.Post != nil {
+			if post := Eval(fs.Post, loopEnv); isAbrupt(post) {
+				return post
+			}
+		}
+	}
+}
+
+// evalForInStatement walks array elements, string characters or object
+// literal keys, binding each in a fresh scope so closures capture the value
+// of their own iteration.
+func evalForInStatement(fis *ast.ForInStatement, env *object.Environment) object.Object {
+	iterable := Eval(fis.Iterable, env)
+	if isAbrupt(iterable) {
+		return iterable
+	}
+
+	var items []object.Object
+	switch iterable := iterable.(type) {
+	case *object.Array:
+		items = iterable.Elements
+	case *object.String:
+		for _, r := range iterable.Value {
+			items = append(items, &object.String{Value: string(r)})
+		}
+	case *object.ObjectLiteral:
+		for _, pair := range iterable.SortedPairs() {
+			items = append(items, pair.Key)
+		}
+	default:
+		return newError("cannot iterate over %s", iterable.Type())
+	}
+
+	for _, item := range items {
+		iterEnv := object.NewEnclosedEnvironment(env)
+		iterEnv.Set(fis.Variable.Value, item)
+
+		if result, stop := loopControl(Eval(fis.Body, iterEnv)); stop {
+			return result
+		}
+	}
+	return NULL
+}
+
 func evalProgram(program *ast.Program, env *object.Environment) object.Object {
 	var result object.Object
 	for _, statement := range program.Statements {
@@ -290,6 +609,88 @@ func evalIdentifier(node *ast.Identifier, env *object.Environment) object.Object
 	return newError("identifier not found: " + node.Value)
 }
 
+func evalAssignExpression(
+	node *ast.AssignExpression,
+	env *object.Environment,
+) object.Object {
+	switch target := node.Target.(type) {
+	case *ast.Identifier:
+		val := Eval(node.Value, env)
+		if isAbrupt(val) {
+			return val
+		}
+		if node.Operator != "=" {
+			current := evalIdentifier(target, env)
+			if isAbrupt(current) {
+				return current
+			}
+			val = evalCompoundOperator(node.Operator, current, val, evaluationOf(env).config.Overflow)
+			if isAbrupt(val) {
+				return val
+			}
+		}
+		if _, ok := env.Assign(target.Value, val); !ok {
+			return newError("cannot assign to undeclared identifier: %s", target.Value)
+		}
+		return val
+	case *ast.IndexExpression:
+		left := Eval(target.Left, env)
+		if isAbrupt(left) {
+			return left
+		}
+		idx := Eval(target.Index, env)
+		if isAbrupt(idx) {
+			return idx
+		}
+		val := Eval(node.Value, env)
+		if isAbrupt(val) {
+			return val
+		}
+		if node.Operator != "=" {
+			current := evalIndexExpression(left, idx)
+			if isAbrupt(current) {
+				return current
+			}
+			val = evalCompoundOperator(node.Operator, current, val, evaluationOf(env).config.Overflow)
+			if isAbrupt(val) {
+				return val
+			}
+		}
+		return evalIndexAssignment(left, idx, val)
+	default:
+		return newError("cannot assign to %s", node.Target.String())
+	}
+}
+
+// evalCompoundOperator applies the infix operator of a compound assignment
+// like += to the current and the assigned value.
+func evalCompoundOperator(operator string, current, val object.Object, policy OverflowPolicy) object.Object {
+	return evalInfixExpression(strings.TrimSuffix(operator, "="), current, val, policy)
+}
+
+func evalIndexAssignment(left, idx, val object.Object) object.Object {
+	switch {
+	case left.Type() == object.ARRAY_OBJ && idx.Type() == object.INTEGER_OBJ:
+		arrObj := left.(*object.Array)
+		index, ok := resolveIndex(idx.(*object.Integer).Value, len(arrObj.Elements))
+		if !ok {
+			return newError("index out of range: %d", idx.(*object.Integer).Value)
+		}
+		arrObj.Elements[index] = val
+		return val
+	case left.Type() == object.OBJ_LITERAL_OBJ:
+		objectLiteral := left.(*object.ObjectLiteral)
+		key, ok := idx.(object.Hashable)
+		if !ok {
+			return newError("Can't hash object of type %s", idx.Type())
+		}
+		objectLiteral.Pairs[key.HashKey()] = object.HashPair{Key: idx, Value: val}
+		return val
+	default:
+		return newError("Index assignment on %s[%s] not supported", left.Type(), idx.Type())
+	}
+}
+
 func newError(format string, a ...interface{}) *object.Error {
 	return &object.Error{Message: fmt.Sprintf(format, a...)}
 }
@@ -301,6 +702,18 @@ func isError(obj object.Object) bool {
 	return false
 }
 
+// isAbrupt reports whether obj cuts the evaluation of a statement short: an
+// error, or a return, break or continue on its way up to the enclosing
+// function or loop. Wherever a value is used, abrupt values are passed up
+// instead, so let x = if (c) { break } leaves the loop rather than binding x.
+func isAbrupt(obj object.Object) bool {
+	switch obj.(type) {
+	case *object.Error, *object.ReturnValue, *object.Break, *object.Continue:
+		return true
+	}
+	return false
+}
+
 func evalExpressions(
 	exps []ast.Expression,
 	env *object.Environment,
@@ -309,7 +722,7 @@ func evalExpressions(
 
 	for _, e := range exps {
 		evaluated := Eval(e, env)
-		if isError(evaluated) {
+		if isAbrupt(evaluated) {
 			return []object.Object{evaluated}
 		}
 		result = append(result, evaluated)
@@ -321,6 +734,8 @@ func evalIndexExpression(left, idx object.Object) object.Object {
 	switch {
 	case left.Type() == object.ARRAY_OBJ && idx.Type() == object.INTEGER_OBJ:
 		return evalArrayIndexExpression(left, idx)
+	case left.Type() == object.STRING_OBJ && idx.Type() == object.INTEGER_OBJ:
+		return evalStringIndexExpression(left, idx)
 	case left.Type() == object.OBJ_LITERAL_OBJ:
 		return evalObjectLiteralIndexExpression(left, idx)
 	default:
@@ -330,16 +745,102 @@ func evalIndexExpression(left, idx object.Object) object.Object {
 
 func evalArrayIndexExpression(arr, idx object.Object) object.Object {
 	arrObj := arr.(*object.Array)
-	index := idx.(*object.Integer).Value
-	max := int64(len(arrObj.Elements) -1)
-	
-	if index < 0 || index > max {
+	index, ok := resolveIndex(idx.(*object.Integer).Value, len(arrObj.Elements))
+	if !ok {
 		return NULL
 	}
 
 	return arrObj.Elements[index]
 }
 
+// resolveIndex counts negative indices from the end of a sequence of the
+// given length and reports whether the result is in range.
+func resolveIndex(index int64, length int) (int64, bool) {
+	if index < 0 {
+		index += int64(length)
+	}
+	return index, index >= 0 && index < int64(length)
+}
+
+func evalSliceExpression(node *ast.SliceExpression, env *object.Environment) object.Object {
+	left := Eval(node.Left, env)
+	if isAbrupt(left) {
+		return left
+	}
+	if left.Type() != object.ARRAY_OBJ && left.Type() != object.STRING_OBJ {
+		return newError("Slice on %s not supported", left.Type())
+	}
+
+	begin, err := evalSliceBound(node.Start, env, 0)
+	if err != nil {
+		return err
+	}
+	end, err := evalSliceBound(node.End, env, math.MaxInt64)
+	if err != nil {
+		return err
+	}
+
+	return sliceObject(left, begin, end)
+}
+
+// evalSliceBound evaluates an optional bound of a slice expression, falling
+// back to def when it was omitted.
+func evalSliceBound(node ast.Expression, env *object.Environment, def int64) (int64, object.Object) {
+	if node == nil {
+		return def, nil
+	}
+	bound := Eval(node, env)
+	if isAbrupt(bound) {
+		return 0, bound
+	}
+	integer, ok := bound.(*object.Integer)
+	if !ok {
+		return 0, newError("slice indices must be integers, got=%s", bound.Type())
+	}
+	return integer.Value, nil
+}
+
+// sliceObject returns the elements of an array, or the characters of a
+// string, from begin up to but not including end. Negative bounds count from
+// the end and both bounds are clamped to the length, so it never fails.
+func sliceObject(obj object.Object, begin, end int64) object.Object {
+	switch obj := obj.(type) {
+	case *object.Array:
+		b, e := sliceBounds(begin, end, len(obj.Elements))
+		elements := make([]object.Object, e-b)
+		copy(elements, obj.Elements[b:e])
+		return &object.Array{Elements: elements}
+	case *object.String:
+		runes := []rune(obj.Value)
+		b, e := sliceBounds(begin, end, len(runes))
+		return &object.String{Value: string(runes[b:e])}
+	default:
+		return newError("Slice on %s not supported", obj.Type())
+	}
+}
+
+func sliceBounds(begin, end int64, length int) (int64, int64) {
+	begin = clampIndex(begin, length)
+	end = clampIndex(end, length)
+	if end < begin {
+		end = begin
+	}
+	return begin, end
+}
+
+func clampIndex(index int64, length int) int64 {
+	if index < 0 {
+		index += int64(length)
+		if index < 0 {
+			return 0
+		}
+	}
+	if index > int64(length) {
+		return int64(length)
+	}
+	return index
+}
+
 func evalObjectLiteralIndexExpression(obj, idx object.Object) object.Object {
 	objectLiteral := obj.(*object.ObjectLiteral)
 
@@ -363,7 +864,7 @@ func evalObjectLiteral(
 
 	for nodeKey, nodeValue := range node.Pairs {
 		key := Eval(nodeKey, env)
-		if isError(key) {
+		if isAbrupt(key) {
 			return key
 		}
 		hashKey, ok := key.(object.Hashable)
@@ -372,7 +873,7 @@ func evalObjectLiteral(
 		}
 
 		val := Eval(nodeValue, env)
-		if isError(val) {
+		if isAbrupt(val) {
 			return val
 		}
 
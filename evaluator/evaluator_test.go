@@ -1,10 +1,15 @@
 package evaluator
 
 import (
+	"bytes"
+	"fmt"
 	"math"
+	"math/rand"
 	"monkey/lexer"
 	"monkey/object"
 	"monkey/parser"
+	"strings"
+	"sync"
 	"testing"
 )
 
@@ -23,6 +28,26 @@ func TestEvalIntegerExpression(t *testing.T) {
 		{"-50 + 200 - 100", 50},
 		{"30 * -1 + 15 * 2", 0},
 		{"50 / 2 * 3 - 5", 70},
+		{"7 % 3", 1},
+		{"-7 % 3", -1},
+		{"2 + 7 % 4 * 2", 8},
+		{"2 ** 10", 1024},
+		{"2 ** 3 ** 2", 512},
+		{"-2 ** 2", -4},
+		{"(-2) ** 3", -8},
+		{"5 ** 0", 1},
+		{"2 ** 64", 0},
+		{"12 & 10", 8},
+		{"12 | 10", 14},
+		{"12 ^ 10", 6},
+		{"~5", -6},
+		{"~-1", 0},
+		{"1 << 4", 16},
+		{"-16 >> 2", -4},
+		{"1 << 64", 0},
+		{"-1 >> 70", -1},
+		{"1 + 1 << 2", 8},
+		{"1 | 2 ^ 3 & 5", 3},
 	}
 
 	for _, tt := range tests {
@@ -32,7 +57,7 @@ func TestEvalIntegerExpression(t *testing.T) {
 }
 
 func testEval(input string) object.Object {
-	l := lexer.New(input)
+	l := lexer.New("", input)
 	p := parser.New(l)
 	program := p.ParseProgram()
 	env := object.NewEnvironment()
@@ -40,6 +65,11 @@ func testEval(input string) object.Object {
 	return Eval(program, env)
 }
 
+func testEvalWith(input string, config Config) object.Object {
+	program := parser.New(lexer.New("", input)).ParseProgram()
+	return Eval(program, NewEnvironment(config))
+}
+
 func testIntegerObject(t *testing.T, obj object.Object, expected int64) bool {
 	result, ok := obj.(*object.Integer)
 	if !ok {
@@ -74,6 +104,23 @@ func TestEvalBooleanExpression(t *testing.T) {
 		{"true != false", true},
 		{"(1 < 2) == true", true},
 		{"(2 < 1) == false", true},
+		{"1 <= 2", true},
+		{"2 <= 2", true},
+		{"3 <= 2", false},
+		{"2 >= 2", true},
+		{"1 >= 2", false},
+		{"1.5 <= 1", false},
+		{"1 >= 0.5", true},
+		{`"a" < "b"`, true},
+		{`"b" <= "a"`, false},
+		{`"abc" >= "abc"`, true},
+		{`"b" > "abc"`, true},
+		{"true && true", true},
+		{"true && false", false},
+		{"false || true", true},
+		{"false || false", false},
+		{"1 < 2 && 2 < 3", true},
+		{"1 > 2 || 2 > 3", false},
 	}
 
 	for _, tt := range tests {
@@ -172,6 +219,19 @@ func testNullObject(t *testing.T, obj object.Object) bool {
 	return true
 }
 
+func testErrorObject(t *testing.T, obj object.Object, expected string) bool {
+	errObj, ok := obj.(*object.Error)
+	if !ok {
+		t.Errorf("object is not Error, got=%T (%+v)", obj, obj)
+		return false
+	}
+	if errObj.Message != expected {
+		t.Errorf("wrong error message, expected=%q, got=%q", expected, errObj.Message)
+		return false
+	}
+	return true
+}
+
 func TestReturnStatements(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -319,25 +379,42 @@ func TestBuiltinParserMethods(t *testing.T) {
 		input    string
 		expected interface{}
 	}{
-		{"parseFloat('abc')", math.NaN},
-		{"parseFloat('3.14abc')", 3.14},
-		{"parseFloat('a3.14')", math.NaN},
-		{"parseFloat(NaN)", math.NaN},
-		{"parseFloat('-4.12')", 4.12},
-		{"parseInt(' 0xF')", 16},
-		{"parseInt('1111')", 1111},
-		{"parseInt('1111', 2)", 15},
-		{"parseInt('15 * 3', 10)", 15},
-		{"parseInt('Hello')", math.NaN},
+		{`parseFloat("abc")`, math.NaN()},
+		{`parseFloat("3.14abc")`, 3.14},
+		{`parseFloat("a3.14")`, math.NaN()},
+		{`parseFloat(0.0 / 0)`, math.NaN()},
+		{`parseFloat("-4.12")`, -4.12},
+		{`parseInt(" 0xF")`, 15},
+		{`parseInt("1111")`, 1111},
+		{`parseInt("1111", 2)`, 15},
+		{`parseInt("15 * 3", 10)`, 15},
+		{`parseInt("Hello")`, math.NaN()},
+		{`parseInt("-ff", 16)`, -255},
+		{`parseInt("0x1A", 16)`, 26},
+		{`parseInt("z", 36)`, 35},
+		{`parseInt("12", 2)`, 1},
+		{`parseInt("2", 2)`, math.NaN()},
+		{`parseInt("+42px")`, 42},
+		{`parseInt("")`, math.NaN()},
+		{`parseInt(4.99)`, 4},
+		{`parseInt(-4.99)`, -4},
+		{`parseInt(7)`, 7},
 	}
 
 	for _, tt := range tests {
 		evaluated := testEval(tt.input)
 
-		// add support for float, nans
 		switch expected := tt.expected.(type) {
 		case int:
 			testIntegerObject(t, evaluated, int64(expected))
+		case float64:
+			if !math.IsNaN(expected) {
+				testFloatObject(t, evaluated, expected)
+				continue
+			}
+			if result, ok := evaluated.(*object.Float); !ok || !math.IsNaN(result.Value) {
+				t.Errorf("expected NaN for %s, got=%T (%+v)", tt.input, evaluated, evaluated)
+			}
 		}
 	}
 }
@@ -368,7 +445,7 @@ func TestArrayIndexExprs(t *testing.T) {
 	}{
 		{
 			"[1,2,3][0]",
-			'a',
+			1,
 		},
 		{
 			"[1,2,3][2]",
@@ -390,6 +467,22 @@ func TestArrayIndexExprs(t *testing.T) {
 			"[1,2,3][-5]",
 			nil,
 		},
+		{
+			"[1,2,3][-1]",
+			3,
+		},
+		{
+			"[1,2,3][-3]",
+			1,
+		},
+		{
+			"[1,2,3][-4]",
+			nil,
+		},
+		{
+			"let a = [1,2,3]; a[-1] = 5; a[2]",
+			5,
+		},
 	}
 
 	for _, tt := range tests {
@@ -484,4 +577,1134 @@ func TestHashIndexExpressions(t *testing.T) {
 			testNullObject(t, evaluated)
 		}
 	}
-}
\ No newline at end of file
+}
+
+func TestLenBuiltin(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{`len("")`, 0},
+		{`len("four")`, 4},
+		{`len("café")`, 4},
+		{`len("日本語 👋")`, 5},
+		{`len([1, 2, 3])`, 3},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestEvalFloatExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected float64
+	}{
+		{"3.5", 3.5},
+		{"-2.5", -2.5},
+		{"1.5 + 1.5", 3},
+		{"1 + 0.5", 1.5},
+		{"0.5 + 1", 1.5},
+		{"10 * 0.1", 1},
+		{"7 / 2.0", 3.5},
+		{"2.5e2 - 50", 200},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testFloatObject(t, evaluated, tt.expected)
+	}
+}
+
+func testFloatObject(t *testing.T, obj object.Object, expected float64) bool {
+	result, ok := obj.(*object.Float)
+	if !ok {
+		t.Errorf("object is not Float, got=%T (%+v)", obj, obj)
+		return false
+	}
+	if result.Value != expected {
+		t.Errorf("object has wrong value, got=%g, want=%g",
+			result.Value, expected)
+		return false
+	}
+	return true
+}
+
+func TestFloatComparison(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"1.5 < 2", true},
+		{"2 > 1.5", true},
+		{"1.0 == 1", true},
+		{"0.1 + 0.2 == 0.3", false},
+		{"2.5 != 2.5", false},
+		{"isNaN(parseFloat(\"abc\"))", true},
+		{"isNaN(1.5)", false},
+		{"isNaN(1)", false},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testBooleanObject(t, evaluated, tt.expected)
+	}
+}
+
+func TestParseFloat(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected float64
+	}{
+		{`parseFloat("3.14abc")`, 3.14},
+		{`parseFloat("  -4.12")`, -4.12},
+		{`parseFloat("1e3")`, 1000},
+		{`parseFloat(".5")`, 0.5},
+		{`parseFloat(2)`, 2},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testFloatObject(t, evaluated, tt.expected)
+	}
+}
+
+func TestFloatObjectLiteralKeys(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`{1.5: 1}[1.5]`, 1},
+		{`{1: 2}[1.0]`, 2},
+		{`{2.0: 3}[2]`, 3},
+		{`{1.5: 1}[2.5]`, nil},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		integer, ok := tt.expected.(int)
+		if ok {
+			testIntegerObject(t, evaluated, int64(integer))
+		} else {
+			testNullObject(t, evaluated)
+		}
+	}
+}
+
+func TestAssignExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"let a = 1; a = 2; a;", 2},
+		{"let a = 1; a = a + 5;", 6},
+		{"let a = 5; a += 2; a;", 7},
+		{"let a = 5; a -= 2; a;", 3},
+		{"let a = 5; a *= 2; a;", 10},
+		{"let a = 6; a /= 2; a;", 3},
+		{"let a = 1; let b = 1; a = b = 3; a + b;", 6},
+		{"let a = 1.5; a += 1; a;", 2.5},
+		{`let s = "a"; s += "b"; s;`, "ab"},
+		{"let counter = fn() { let c = 0; fn() { c += 1 } }(); counter(); counter();", 2},
+		{"let a = 0; let f = fn() { a = 10; }; f(); a;", 10},
+		{"let f = fn(a) { a = 3; a }; f(1);", 3},
+		{"let arr = [1, 2, 3]; arr[1] = 5; arr[1];", 5},
+		{"let arr = [1, 2, 3]; arr[2] += 5; arr[2];", 8},
+		{`let obj = {"k": 1}; obj["k"] = 2; obj["k"];`, 2},
+		{`let obj = {}; obj["new"] = 3; obj["new"];`, 3},
+		{`let obj = {"k": [1]}; obj["k"][0] *= 4; obj["k"][0];`, 4},
+		{"b = 1;", "cannot assign to undeclared identifier: b"},
+		{"let f = fn() { let local = 1; }; f(); local = 2;", "cannot assign to undeclared identifier: local"},
+		{"b += 1;", "identifier not found: b"},
+		{"let arr = [1]; arr[3] = 1;", "index out of range: 3"},
+		{`let a = 1; a += "x";`, "unknown operator: INTEGER + STRING"},
+		{"let obj = {}; obj[fn() {}] = 1;", "Can't hash object of type FUNCTION"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case float64:
+			testFloatObject(t, evaluated, expected)
+		case string:
+			if str, ok := evaluated.(*object.String); ok {
+				if str.Value != expected {
+					t.Errorf("String has wrong value, got=%q, want=%q", str.Value, expected)
+				}
+				continue
+			}
+			testErrorObject(t, evaluated, expected)
+		}
+	}
+}
+
+func TestLoops(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"let i = 0; while (i < 5) { i += 1; } i;", 5},
+		{"let i = 0; while (true) { i += 1; if (i == 3) { break; } } i;", 3},
+		{"let s = 0; let i = 0; while (i < 5) { i += 1; if (i == 2) { continue; } s += i; } s;", 13},
+		{"let s = 0; for (let i = 0; i < 4; i += 1) { s += i; } s;", 6},
+		{"let s = 0; for (let i = 0; i < 10; i += 1) { if (i > 3) { break; } s += i; } s;", 6},
+		{"let s = 0; for (let i = 0; i < 5; i += 1) { if (i == 1) { continue; } s += i; } s;", 9},
+		{"let s = 0; for (x in [1, 2, 3]) { s += x; } s;", 6},
+		{`let s = ""; for (c in "héllo") { s = c + s; } s;`, "olléh"},
+		{`let s = ""; for (k in {"b": 1, "a": 2}) { s += k; } s;`, "ab"},
+		{"let s = 0; for (x in [1, 2, 3]) { for (y in [1, 2, 3]) { if (y > x) { break; } s += 1; } } s;", 6},
+		{"let f = fn() { for (x in [1, 2, 3]) { if (x == 2) { return x * 10; } } }; f();", 20},
+		{"let f = fn() { while (true) { return 7; } }; f();", 7},
+		{"let fs = []; for (x in [1, 2]) { fs = push(fs, fn() { x }); } fs[0]() + fs[1]();", 3},
+		{"let i = 0; while (true) { i += 1; let x = if (i > 3) { break }; } i;", 4},
+		{"let s = 0; for (x in [1, 2, 3]) { s += if (x == 2) { continue } else { x }; } s;", 4},
+		{"let s = 0; for (x in [1, 2, 3]) { s = s + [if (x == 3) { break } else { x }][0]; } s;", 3},
+		{"let f = fn() { let x = if (true) { return 5 }; 10 }; f();", 5},
+		{"let i = 0; while (i < 3) { i += 1; }; i;", 3},
+		{"let s = 0; for (x in [1, 2]) { s += x; }; s;", 3},
+		{"for (x in 5) { }", "cannot iterate over INTEGER"},
+		{"while (y) { }", "identifier not found: y"},
+		{"for (let i = 0; i < 3; i += 1) { i + true; }", "unknown operator: INTEGER + BOOLEAN"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			if str, ok := evaluated.(*object.String); ok {
+				if str.Value != expected {
+					t.Errorf("String has wrong value, got=%q, want=%q", str.Value, expected)
+				}
+				continue
+			}
+			testErrorObject(t, evaluated, expected)
+		}
+	}
+}
+
+func TestLoopEvaluatesToNull(t *testing.T) {
+	evaluated := testEval("while (false) { 1 }")
+	if evaluated != NULL {
+		t.Errorf("loop did not evaluate to NULL, got=%T (%+v)", evaluated, evaluated)
+	}
+}
+
+func TestLogicalOperators(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"0 || 5", 0},
+		{"let n = if (false) { 1 }; n || 5", 5},
+		{`"" && 5`, 5},
+		{"false && 5", false},
+		{"let calls = 0; let f = fn() { calls += 1; true }; false && f(); calls;", 0},
+		{"let calls = 0; let f = fn() { calls += 1; true }; true || f(); calls;", 0},
+		{"let calls = 0; let f = fn() { calls += 1; true }; true && f(); calls;", 1},
+		{"false && undefined", false},
+		{"true || undefined", true},
+		{"true && undefined", "identifier not found: undefined"},
+		{"undefined || true", "identifier not found: undefined"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		case string:
+			testErrorObject(t, evaluated, expected)
+		}
+	}
+}
+
+func TestArithmeticAndBitwiseOperators(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"2 ** -1", 0.5},
+		{"2.0 ** 3", 8.0},
+		{"7.5 % 2", 1.5},
+		{"-7.5 % 2", -1.5},
+		{"1 << -1", "negative shift count: -1"},
+		{"1 >> -3", "negative shift count: -3"},
+		{"1.5 & 1", "unknown operator: FLOAT & INTEGER"},
+		{"~1.5", "unknown operator: ~FLOAT"},
+		{`~"a"`, "unknown operator: ~STRING"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case float64:
+			testFloatObject(t, evaluated, expected)
+		case string:
+			testErrorObject(t, evaluated, expected)
+		}
+	}
+}
+
+func TestIntegerDivisionByZero(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"5 / 0", "division by zero"},
+		{"5 % 0", "modulo by zero"},
+		{"let a = 1; a /= 0;", "division by zero"},
+		{"let f = fn(x) { 10 / x }; f(0);", "division by zero"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testErrorObject(t, evaluated, tt.expected)
+	}
+
+	testFloatObject(t, testEval("1.0 / 0"), math.Inf(1))
+}
+
+func TestIntegerOverflowPolicy(t *testing.T) {
+	tests := []struct {
+		input    string
+		wrap     int64
+		error    string
+		promoted string
+	}{
+		{"9223372036854775807 + 1", math.MinInt64, "integer overflow: 9223372036854775807 + 1", "9223372036854775808"},
+		{"-9223372036854775807 - 2", math.MaxInt64, "integer overflow: -9223372036854775807 - 2", "-9223372036854775809"},
+		{"4294967296 * 4294967296", 0, "integer overflow: 4294967296 * 4294967296", "18446744073709551616"},
+		{"2 ** 64", 0, "integer overflow: 2 ** 64", "18446744073709551616"},
+		{"3 ** 40", -6289078614652622815, "integer overflow: 3 ** 40", "12157665459056928801"},
+		{"let m = -9223372036854775807 - 1; m / -1", math.MinInt64, "integer overflow: -9223372036854775808 / -1", "9223372036854775808"},
+		{"let m = -9223372036854775807 - 1; -m", math.MinInt64, "integer overflow: 0 - -9223372036854775808", "9223372036854775808"},
+		{"let a = 9223372036854775807; a += 1; a", math.MinInt64, "integer overflow: 9223372036854775807 + 1", "9223372036854775808"},
+		{"let inc = fn(a) { a + 1 }; inc(9223372036854775807)", math.MinInt64, "integer overflow: 9223372036854775807 + 1", "9223372036854775808"},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEvalWith(tt.input, Config{Overflow: OverflowWrap}), tt.wrap)
+
+		evaluated := testEvalWith(tt.input, Config{Overflow: OverflowError})
+		testErrorObject(t, evaluated, tt.error)
+
+		evaluated = testEvalWith(tt.input, Config{Overflow: OverflowPromote})
+		bigInt, ok := evaluated.(*object.BigInteger)
+		if !ok {
+			t.Errorf("object is not BigInteger for %q, got=%T (%+v)", tt.input, evaluated, evaluated)
+		} else if bigInt.Inspect() != tt.promoted {
+			t.Errorf("BigInteger has wrong value, expected=%s, got=%s", tt.promoted, bigInt.Inspect())
+		}
+	}
+
+	strict := Config{Overflow: OverflowError}
+	testIntegerObject(t, testEvalWith("9223372036854775806 + 1", strict), math.MaxInt64)
+	testIntegerObject(t, testEvalWith("-3037000499 * 3037000499", strict), -9223372030926249001)
+	testIntegerObject(t, testEvalWith("2 ** 62", strict), 1<<62)
+	testErrorObject(t, testEvalWith("pow(2, 64)", strict), "integer overflow: 2 ** 64")
+}
+
+func TestAbsOverflow(t *testing.T) {
+	input := "abs(-9223372036854775807 - 1)"
+	for _, policy := range []OverflowPolicy{OverflowWrap, OverflowError} {
+		testErrorObject(t, testEvalWith(input, Config{Overflow: policy}), "integer overflow: abs(-9223372036854775808)")
+	}
+
+	evaluated := testEvalWith(input, Config{Overflow: OverflowPromote})
+	if bigInt, ok := evaluated.(*object.BigInteger); !ok || bigInt.Inspect() != "9223372036854775808" {
+		t.Errorf("abs() was not promoted, got=%T (%+v)", evaluated, evaluated)
+	}
+}
+
+func TestBigIntegerExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"123456789012345678901234567890n", "123456789012345678901234567890"},
+		{"9223372036854775807n + 1", "9223372036854775808"},
+		{"9223372036854775807 + 1n", "9223372036854775808"},
+		{"2n ** 100", "1267650600228229401496703205376"},
+		{"100000000000000000000n * 100000000000000000000n - 1", "9999999999999999999999999999999999999999"},
+		{"-7n / 2", "-3"},
+		{"-7n % 2", "-1"},
+		{"-(5n)", "-5"},
+		{"~0n", "-1"},
+		{"12n & 10 | 1n", "9"},
+		{"6n ^ 3", "5"},
+		{"1n << 70", "1180591620717411303424"},
+		{"-1n >> 1000", "-1"},
+		{"1180591620717411303424n >> 70", "1"},
+		{"let a = 1n; a *= 99999999999; a *= 99999999999; a;", "9999999999800000000001"},
+		{"10n > 9", true},
+		{"5n == 5", true},
+		{"5 != 5n", false},
+		{"99999999999999999999n <= 99999999999999999999n", true},
+		{"1n < 0.5", false},
+		{"1n + 0.5", 1.5},
+		{"2n ** -1", 0.5},
+		{"5n / 0", "division by zero"},
+		{"5n % 0n", "modulo by zero"},
+		{"1n << -1", "negative shift count: -1"},
+		{"1n << 99999999999", "shift count too large: 99999999999"},
+		{"2n ** 99999999999", "exponent too large: 99999999999"},
+		{`1n + "a"`, "unknown operator: BIG_INTEGER + STRING"},
+		{`bigint("123456789012345678901234567890") + 0`, "123456789012345678901234567890"},
+		{`bigint(" -0x_ff ")`, "-255"},
+		{`bigint("42n")`, "42"},
+		{`bigint("010")`, "10"},
+		{`bigint("-08")`, "-8"},
+		{`bigint("0o10")`, "8"},
+		{"bigint(42)", "42"},
+		{"bigint(1e20)", "100000000000000000000"},
+		{"bigint(1.5)", "bigint() cannot convert 1.5, not an integer"},
+		{`bigint("12abc")`, `bigint() cannot parse "12abc"`},
+		{"bigint([])", "bigint() accepts only strings / numbers, got=ARRAY"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		case float64:
+			testFloatObject(t, evaluated, expected)
+		case string:
+			if bigInt, ok := evaluated.(*object.BigInteger); ok {
+				if bigInt.Inspect() != expected {
+					t.Errorf("BigInteger has wrong value for %q, expected=%s, got=%s",
+						tt.input, expected, bigInt.Inspect())
+				}
+				continue
+			}
+			testErrorObject(t, evaluated, expected)
+		}
+	}
+}
+
+func TestBigIntegerObjectLiteralKeys(t *testing.T) {
+	input := `let obj = {5n: "small", 123456789012345678901234567890n: "huge"};
+[obj[5], obj[123456789012345678901234567890n]]`
+
+	evaluated := testEval(input)
+	arr, ok := evaluated.(*object.Array)
+	if !ok {
+		t.Fatalf("object is not Array, got=%T (%+v)", evaluated, evaluated)
+	}
+	if arr.Inspect() != "[small, huge]" {
+		t.Errorf("wrong lookups, got=%s", arr.Inspect())
+	}
+}
+
+func TestStringOperators(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`"a" == "a"`, true},
+		{`"a" + "b" == "ab"`, true},
+		{`"a" != "a"`, false},
+		{`"a" != "b"`, true},
+		{`let f = fn() { "x" }; f() == f()`, true},
+		{`"apple" < "banana"`, true},
+		{`"b" <= "b"`, true},
+		{`"ab" * 3`, "ababab"},
+		{`2 * "xy"`, "xyxy"},
+		{`"ab" * 0`, ""},
+		{`let s = "-"; s *= 3; s;`, "---"},
+		{`"ab" * -1`, "negative repeat count: -1"},
+		{`"ab" * 999999999999`, "repeated string too long: 999999999999 * 2 bytes"},
+		{`"a" - "b"`, "unknown operator: STRING - STRING"},
+		{`"ab" * 1.5`, "unknown operator: STRING * FLOAT"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		case string:
+			if str, ok := evaluated.(*object.String); ok {
+				if str.Value != expected {
+					t.Errorf("String has wrong value, got=%q, want=%q", str.Value, expected)
+				}
+				continue
+			}
+			testErrorObject(t, evaluated, expected)
+		}
+	}
+}
+
+func TestInOperator(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`"ell" in "hello"`, true},
+		{`"" in "hello"`, true},
+		{`"x" in "hello"`, false},
+		{`2 in [1, 2, 3]`, true},
+		{`4 in [1, 2, 3]`, false},
+		{`2.0 in [1, 2, 3]`, true},
+		{`"b" in ["a", "b"]`, true},
+		{`5n in [5]`, true},
+		{`true in [1, "true"]`, false},
+		{`"a" in {"a": 1}`, true},
+		{`"b" in {"a": 1}`, false},
+		{`1 in {1.0: "x"}`, true},
+		{`10000000000000000000n in {1e19: "x"}`, true},
+		{`1e19 in {10000000000000000000n: "x"}`, true},
+		{`!("a" in "abc")`, false},
+		{`1 in "abc"`, "unknown operator: INTEGER in STRING"},
+		{`1 in 2`, "unknown operator: INTEGER in INTEGER"},
+		{`[] in {}`, "Can't hash object of type ARRAY"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		case string:
+			testErrorObject(t, evaluated, expected)
+		}
+	}
+}
+
+func TestDeepEquality(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"[1, 2] == [1, 2]", true},
+		{"[1, 2] != [1, 2]", false},
+		{"[1, 2] == [2, 1]", false},
+		{"[1, 2] == [1, 2, 3]", false},
+		{"[1, [2, [3]]] == [1, [2, [3]]]", true},
+		{"[1, 2.0] == [1.0, 2]", true},
+		{`{"a": 1} == {"a": 1}`, true},
+		{`{"a": 1, "b": [1]} == {"b": [1], "a": 1}`, true},
+		{`{"a": 1} == {"a": 2}`, false},
+		{`{"a": 1} == {"b": 1}`, false},
+		{`{"a": 1} == {"a": 1, "b": 2}`, false},
+		{`[] == {}`, false},
+		{`[1] == 1`, false},
+		{`[[1, 2]] == [[1, 2]]`, true},
+		{"let a = [1]; a == a", true},
+		{"let f = fn() {}; f == f", true},
+		{"fn() {} == fn() {}", false},
+		{"1 == true", false},
+		{"let a = [0]; a[0] = a; let b = [0]; b[0] = b; a == b", true},
+		{`[1, 2] in [[3], [1, 2]]`, true},
+		{`[{"a": 1}] == [{"a": 1}]`, true},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testBooleanObject(t, evaluated, tt.expected)
+	}
+}
+
+func TestConversionBuiltins(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`int("42")`, 42},
+		{`int(" -17 ")`, -17},
+		{`int("0x1f")`, 31},
+		{`int("1_000")`, 1000},
+		{`int("010")`, 10},
+		{`int("08")`, 8},
+		{`int("-007")`, -7},
+		{`int("0")`, 0},
+		{`int("0b101")`, 5},
+		{`int(3.99)`, 3},
+		{`int(-3.99)`, -3},
+		{`int(true)`, 1},
+		{`int(false)`, 0},
+		{`int(7)`, 7},
+		{`type(int("123456789012345678901234567890"))`, "BIG_INTEGER"},
+		{`int("4.2")`, `int() cannot parse "4.2"`},
+		{`int("12abc")`, `int() cannot parse "12abc"`},
+		{`int("")`, `int() cannot parse ""`},
+		{`int(0.0 / 0)`, "int() cannot convert NaN"},
+		{`int([1])`, "int() accepts only strings / numbers / booleans, got=ARRAY"},
+		{`int(1, 2)`, "int() accepts single parameter, got=2"},
+		{`str(42)`, "42"},
+		{`str(1.5)`, "1.5"},
+		{`str(2.0)`, "2.0"},
+		{`str(12345678901234567890n)`, "12345678901234567890"},
+		{`str(true)`, "true"},
+		{`str("s")`, "s"},
+		{`str([1, "a"])`, "[1, a]"},
+		{`str()`, "str() accepts single parameter, got=0"},
+		{`bool(0)`, true},
+		{`bool(false)`, false},
+		{`bool(if (false) { 1 })`, false},
+		{`bool("")`, true},
+		{`type(1)`, "INTEGER"},
+		{`type(1.5)`, "FLOAT"},
+		{`type("a")`, "STRING"},
+		{`type(true)`, "BOOLEAN"},
+		{`type([])`, "ARRAY"},
+		{`type({})`, "HASH"},
+		{`type(fn() {})`, "FUNCTION"},
+		{`type(len)`, "BUILTIN"},
+		{`type(if (false) { 1 })`, "NULL"},
+		{`int(str(123)) == 123`, true},
+		{`parseInt("10", 1)`, "parseInt() base must be between 2 and 36, got=1"},
+		{`parseInt("10", "2")`, "parseInt() base must be an integer, got=STRING"},
+		{`parseInt([])`, "parseInt() accepts only strings / numbers, got=ARRAY"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		case string:
+			switch result := evaluated.(type) {
+			case *object.String:
+				if result.Value != expected {
+					t.Errorf("String has wrong value for %s, got=%q, want=%q", tt.input, result.Value, expected)
+				}
+			case *object.Error:
+				if result.Message != expected {
+					t.Errorf("wrong error message, expected=%q, got=%q", expected, result.Message)
+				}
+			default:
+				t.Errorf("expected String or Error for %s, got=%T (%+v)", tt.input, evaluated, evaluated)
+			}
+		}
+	}
+}
+
+func TestOutputBuiltins(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`puts("a", 1, [2])`, "a\n1\n[2]\n"},
+		{`puts()`, ""},
+		{"let n = 0; while (n < 3) { n += 1; puts(if (n == 2) { continue } else { n }) }", "1\n3\n"},
+		{"while (true) { puts(if (true) { break }) }", ""},
+		{`print("a", 1); print("b")`, "a 1b"},
+		{`printf("%d-%s|%5.2f|%t\n", 42, "x", 3.14159, true)`, "42-x| 3.14|true\n"},
+		{`printf("%v %v %q", [1, "a"], 1.0, "hi\n")`, `[1, a] 1.0 "hi\n"`},
+		{`printf("%x %X %o %b %08b", 255, 255, 8, 5, 5)`, "ff FF 10 101 00000101"},
+		{`printf("%d", 123456789012345678901234567890n)`, "123456789012345678901234567890"},
+		{`printf("%.1f %e", 2, 10n)`, "2.0 1.000000e+01"},
+		{`printf("%T %T %-6s|", 1, "a", "ab")`, "INTEGER STRING ab    |"},
+		{`printf("100%%")`, "100%"},
+	}
+
+	for _, tt := range tests {
+		var out bytes.Buffer
+		evaluated := testEvalWith(tt.input, Config{Output: &out})
+		if evaluated != NULL {
+			t.Errorf("%s did not evaluate to NULL, got=%T (%+v)", tt.input, evaluated, evaluated)
+		}
+		if out.String() != tt.expected {
+			t.Errorf("wrong output for %s, expected=%q, got=%q", tt.input, tt.expected, out.String())
+		}
+	}
+}
+
+func TestFormatBuiltin(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`format("%s=%d", "x", 5)`, "x=5"},
+		{`format("%+d|%5s|%-5d|", 5, "ab", 3)`, "+5|   ab|3    |"},
+		{`format("plain")`, "plain"},
+		{`format("%s", {"a": 1})`, `{a: 1}`},
+		{`format("%d", "x")`, "format() cannot format STRING with %d"},
+		{`format("%t", 1)`, "format() cannot format INTEGER with %t"},
+		{`format("%q", 1)`, "format() cannot format INTEGER with %q"},
+		{`format("%d %d", 1)`, "format() is missing an argument for %d"},
+		{`format("%d", 1, 2)`, "format() got 2 arguments, but the format uses 1"},
+		{`format("%z", 1)`, "format() has no verb %z"},
+		{`format("50%")`, `format() format ends in an incomplete verb "%"`},
+		{`format(1)`, "format() format must be a string, got=INTEGER"},
+		{`format()`, "format() needs a format string"},
+		{`printf("%d", "x")`, "printf() cannot format STRING with %d"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch result := evaluated.(type) {
+		case *object.String:
+			if result.Value != tt.expected {
+				t.Errorf("String has wrong value for %s, got=%q, want=%q", tt.input, result.Value, tt.expected)
+			}
+		case *object.Error:
+			if result.Message != tt.expected {
+				t.Errorf("wrong error message, expected=%q, got=%q", tt.expected, result.Message)
+			}
+		default:
+			t.Errorf("expected String or Error for %s, got=%T (%+v)", tt.input, evaluated, evaluated)
+		}
+	}
+}
+
+func TestStringBuiltins(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`join(split("a,b,c", ","), "-")`, "a-b-c"},
+		{`len(split("héllo", ""))`, 5},
+		{`split("abc", ",")[0]`, "abc"},
+		{`join([1, "a", true])`, "1atrue"},
+		{`join([], ", ")`, ""},
+		{`trim("  hi \n")`, "hi"},
+		{`trim("xxhixx", "x")`, "hi"},
+		{`upper("héllo")`, "HÉLLO"},
+		{`lower("ÀB")`, "àb"},
+		{`replace("a-b-c", "-", "+")`, "a+b+c"},
+		{`replace("a-b-c", "-", "+", 1)`, "a+b-c"},
+		{`contains("hello", "ell")`, true},
+		{`contains("hello", "xyz")`, false},
+		{`startsWith("hello", "he")`, true},
+		{`endsWith("hello", "he")`, false},
+		{`indexOf("héllo", "llo")`, 2},
+		{`indexOf("hello", "z")`, -1},
+		{`indexOf([1, [2], 3], [2])`, 1},
+		{`indexOf([1, 2], 5)`, -1},
+		{`repeat("ab", 3)`, "ababab"},
+		{`padLeft("7", 3, "0")`, "007"},
+		{`padRight("ab", 5)`, "ab   "},
+		{`padLeft("abc", 8, "12")`, "12121abc"},
+		{`padLeft("abcdef", 3)`, "abcdef"},
+		{`padLeft("abc", -9223372036854775807 - 1)`, "abc"},
+		{`"héllo"[1]`, "é"},
+		{`"hello"[0] + "hello"[4]`, "ho"},
+		{`split()`, "split(str, separator) parameters are:\n\t\t\t\tstr: string to split,\n\t\t\t\tseparator: string between the parts, \"\" splits into characters,\n\t\t\t"},
+		{`split(1, ",")`, "split() only supports strings, got=INTEGER"},
+		{`split("a", 1)`, "split() second parameter must be a string, got=INTEGER"},
+		{`join("abc")`, "join() only supports arrays, got=STRING"},
+		{`join([1], 2)`, "join() separator must be a string, got=INTEGER"},
+		{`trim(1)`, "trim() only supports strings, got=INTEGER"},
+		{`upper(1)`, "upper() only supports strings, got=INTEGER"},
+		{`lower("a", "b")`, "lower() accepts single parameter, got=2"},
+		{`replace("a", "b", 1)`, "replace() parameter 3 must be a string, got=INTEGER"},
+		{`replace("a", "b", "c", "d")`, "replace() count must be an integer, got=STRING"},
+		{`indexOf(1, 1)`, "indexOf() only supports strings / arrays, got=INTEGER"},
+		{`indexOf("a", 1)`, "indexOf() needle must be a string, got=INTEGER"},
+		{`repeat("a", -1)`, "negative repeat count: -1"},
+		{`repeat("a", "b")`, "repeat() count must be an integer, got=STRING"},
+		{`padLeft("a", "b")`, "padLeft() length must be an integer, got=STRING"},
+		{`padRight("a", 3, 1)`, "padRight() pad must be a string, got=INTEGER"},
+		{`padLeft("x", 9223372036854775807, "😀😀😀😀")`, "padLeft() result too long: 9223372036854775807 characters"},
+		{`padRight("x", 9223372036854775807)`, "padRight() result too long: 9223372036854775807 characters"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		case string:
+			switch result := evaluated.(type) {
+			case *object.String:
+				if result.Value != expected {
+					t.Errorf("String has wrong value for %s, got=%q, want=%q", tt.input, result.Value, expected)
+				}
+			case *object.Error:
+				if result.Message != expected {
+					t.Errorf("wrong error message, expected=%q, got=%q", expected, result.Message)
+				}
+			default:
+				t.Errorf("expected String or Error for %s, got=%T (%+v)", tt.input, evaluated, evaluated)
+			}
+		}
+	}
+}
+
+func TestStringIndexOutOfRange(t *testing.T) {
+	for _, input := range []string{`"abc"[3]`, `"abc"[-4]`, `""[0]`, `""[-1]`} {
+		evaluated := testEval(input)
+		if evaluated != NULL {
+			t.Errorf("%s did not evaluate to NULL, got=%T (%+v)", input, evaluated, evaluated)
+		}
+	}
+}
+
+func TestMathBuiltins(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"abs(-5)", 5},
+		{"abs(5)", 5},
+		{"abs(-2.5)", 2.5},
+		{"str(abs(-123456789012345678901234567890n))", "123456789012345678901234567890"},
+		{"min(3, 1, 2)", 1},
+		{"max(3, 1, 2)", 3},
+		{"max([4, 9, 2])", 9},
+		{"min(1, 0.5)", 0.5},
+		{"max(1, 1.0)", 1},
+		{"max(1, 99999999999999999999n) == 99999999999999999999n", true},
+		{"isNaN(max(1, 0.0 / 0))", true},
+		{"pow(2, 10)", 1024},
+		{"pow(2, 0.5) == sqrt(2)", true},
+		{"sqrt(16)", 4.0},
+		{"isNaN(sqrt(-1))", true},
+		{"floor(2.7)", 2},
+		{"floor(-2.1)", -3},
+		{"ceil(2.1)", 3},
+		{"round(2.5)", 3},
+		{"round(-2.5)", -3},
+		{"round(2.4)", 2},
+		{"floor(7)", 7},
+		{"clamp(5, 0, 3)", 3},
+		{"clamp(-1, 0, 3)", 0},
+		{"clamp(1.5, 0, 3)", 1.5},
+		{`abs("1")`, "abs() only supports numbers, got=STRING"},
+		{"abs(1, 2)", "abs() accepts single parameter, got=2"},
+		{`min(1, "a")`, "min() only supports numbers, got=STRING"},
+		{`max([])`, "max(...numbers) parameters are:\n\t\t\t\tnumbers: numbers to compare, or a single array of them,\n\t\t\t"},
+		{`pow(2, "a")`, "pow() only supports numbers, got=STRING"},
+		{"floor(0.0 / 0)", "floor() cannot convert NaN"},
+		{"clamp(1, 3, 0)", "clamp() min 3 is greater than max 0"},
+		{"random(1)", "random() accepts no parameters, got=1"},
+		{"randomInt(5, 5)", "randomInt() range [5, 5) is empty"},
+		{"randomInt(1.5)", "randomInt() only supports integers, got=FLOAT"},
+		{"randomInt(-9223372036854775807, 9223372036854775807)", "randomInt() range [-9223372036854775807, 9223372036854775807) is too large"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case float64:
+			testFloatObject(t, evaluated, expected)
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		case string:
+			switch result := evaluated.(type) {
+			case *object.String:
+				if result.Value != expected {
+					t.Errorf("String has wrong value for %s, got=%q, want=%q", tt.input, result.Value, expected)
+				}
+			case *object.Error:
+				if result.Message != expected {
+					t.Errorf("wrong error message, expected=%q, got=%q", expected, result.Message)
+				}
+			default:
+				t.Errorf("expected String or Error for %s, got=%T (%+v)", tt.input, evaluated, evaluated)
+			}
+		}
+	}
+}
+
+func TestSeededRandom(t *testing.T) {
+	input := "[random(), randomInt(10), randomInt(-5, 5), random()]"
+
+	first := testEvalWith(input, Config{Random: rand.New(rand.NewSource(7))}).Inspect()
+	second := testEvalWith(input, Config{Random: rand.New(rand.NewSource(7))}).Inspect()
+
+	if first != second {
+		t.Errorf("same seed gave different numbers: %s and %s", first, second)
+	}
+
+	for i := 0; i < 100; i++ {
+		n := testEval("randomInt(-2, 3)").(*object.Integer).Value
+		if n < -2 || n >= 3 {
+			t.Fatalf("randomInt(-2, 3) out of range, got=%d", n)
+		}
+		f := testEval("random()").(*object.Float).Value
+		if f < 0 || f >= 1 {
+			t.Fatalf("random() out of range, got=%g", f)
+		}
+	}
+}
+
+func TestCollectionBuiltins(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"sort([3, 1.5, 2, -1])", "[-1, 1.5, 2, 3]"},
+		{`sort(["b", "c", "a"])`, "[a, b, c]"},
+		{"sort([3, 1, 2], fn(a, b) { b - a })", "[3, 2, 1]"},
+		{`sort([[2, "b"], [1, "a"], [2, "a"]], fn(a, b) { a[0] - b[0] })`, "[[1, a], [2, b], [2, a]]"},
+		{"let a = [2, 1]; sort(a); a", "[2, 1]"},
+		{"range(4)", "[0, 1, 2, 3]"},
+		{"range(2, 5)", "[2, 3, 4]"},
+		{"range(0, 10, 3)", "[0, 3, 6, 9]"},
+		{"range(5, 0, -2)", "[5, 3, 1]"},
+		{"range(5, 0)", "[]"},
+		{"range(-5, 9223372036854775807, 4611686018427387904)", "[-5, 4611686018427387899, 9223372036854775803]"},
+		{"range(9223372036854775807, -9223372036854775807 - 1, -9223372036854775807 - 1)", "[9223372036854775807, -1]"},
+		{`keys({"b": 1, "a": 2, 3: 4})`, "[3, a, b]"},
+		{`values({"b": 1, "a": 2})`, "[2, 1]"},
+		{`entries({"b": 1, "a": 2})`, "[[a, 2], [b, 1]]"},
+		{`has({"a": 1}, "a")`, "true"},
+		{`has({"a": 1}, "b")`, "false"},
+		{`let o = {"a": 1, "b": 2}; [delete(o, "a"), o]`, "[{b: 2}, {a: 1, b: 2}]"},
+		{`keys(merge({"a": 1, "b": 1}, {"b": 2}, {"c": 3}))`, "[a, b, c]"},
+		{`merge({"a": 1, "b": 1}, {"b": 2})["b"]`, "2"},
+		{`zip([1, 2, 3], ["a", "b"])`, "[[1, a], [2, b]]"},
+		{"reverse([1, 2, 3])", "[3, 2, 1]"},
+		{`reverse("héllo")`, "olléh"},
+		{"flatten([1, [2, [3, [4]]]])", "[1, 2, [3, [4]]]"},
+		{"flatten([1, [2, [3, [4]]]], 5)", "[1, 2, 3, 4]"},
+		{"flatten([1, [2]], 0)", "[1, [2]]"},
+		{"let a = [0]; a[0] = a; len(flatten(a, 100000000))", "1"},
+		{"let a = [1]; let b = [a, [2, a]]; flatten(b, 100000000)", "[1, 2, 1]"},
+		{"let a = [1, 0]; let b = [2, a]; a[1] = b; len(flatten(a, 100000000))", "3"},
+		{"unique([1, 2, 1, 1.0, [3], [3], 2])", "[1, 2, [3]]"},
+		{"find([1, 5, 10], fn(x) { x > 3 })", "5"},
+		{"find([1, 2], fn(x) { x > 3 })", "null"},
+		{"findIndex([1, 5, 10], fn(x) { x > 3 })", "1"},
+		{"findIndex([1, 2], fn(x) { x > 3 })", "-1"},
+		{"some([1, 5], fn(x) { x > 3 })", "true"},
+		{"some([], fn(x) { true })", "false"},
+		{"every([4, 5], fn(x) { x > 3 })", "true"},
+		{"every([4, 1], fn(x) { x > 3 })", "false"},
+		{"findIndex([false, true], bool)", "1"},
+		{"let s = 0; each([5, 6, 7], fn(x, i) { s += x * i }); s", "20"},
+		{"let s = 0; each([5, 6, 7], fn(x) { s += x }); s", "18"},
+		{`each(["ab", "c"], len)`, "null"},
+		{"sort(1)", "sort() only supports arrays, got=INTEGER"},
+		{`sort([1, "a"])`, "sort() cannot compare STRING with INTEGER"},
+		{`sort([1, 2], fn(a, b) { "x" })`, "sort() comparator must return a number, got=STRING"},
+		{"sort([1, 2], fn(a, b) { a + true })", "unknown operator: INTEGER + BOOLEAN"},
+		{"sort([1], 2)", "sort() fn must be a function, got=INTEGER"},
+		{"range(1, 2, 0)", "range() step must not be zero"},
+		{"range(1.5)", "range() only supports integers, got=FLOAT"},
+		{"range(0, 99999999999)", "range() would create more than 16777216 elements"},
+		{"range(-9223372036854775807 - 1, 9223372036854775807)", "range() would create more than 16777216 elements"},
+		{"keys([])", "keys() only supports object literals, got=ARRAY"},
+		{`has({}, [])`, "Can't hash object of type ARRAY"},
+		{`delete([], 1)`, "delete() only supports object literals, got=ARRAY"},
+		{`merge({}, 1)`, "merge() only supports object literals, got=INTEGER"},
+		{"zip([1], 2)", "zip() only supports arrays, got=INTEGER"},
+		{"reverse(1)", "reverse() only supports arrays / strings, got=INTEGER"},
+		{"flatten([], -1)", "flatten() depth must be a non-negative integer, got=-1"},
+		{"unique(1)", "unique() only supports arrays, got=INTEGER"},
+		{"find([1], 1)", "find() fn must be a function, got=INTEGER"},
+		{"findIndex(1, len)", "findIndex() only supports arrays, got=INTEGER"},
+		{"some([1], fn(x) { x + true })", "unknown operator: INTEGER + BOOLEAN"},
+		{"every([1], fn(x) { x / 0 })", "division by zero"},
+		{"each([1, 2], fn(x, i) { if (i == 1) { x / 0 } })", "division by zero"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if errObj, ok := evaluated.(*object.Error); ok {
+			if errObj.Message != tt.expected {
+				t.Errorf("wrong error message for %s, expected=%q, got=%q", tt.input, tt.expected, errObj.Message)
+			}
+			continue
+		}
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("wrong result for %s, expected=%s, got=%s", tt.input, tt.expected, evaluated.Inspect())
+		}
+	}
+}
+
+func TestHigherOrderBuiltins(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"map([1, 2, 3], fn(x) { x * 2 })", "[2, 4, 6]"},
+		{`map(["a", "bc", [1, 2, 3]], len)`, "[1, 2, 3]"},
+		{"map([1, 2], str)", "[1, 2]"},
+		{"map([], fn(x) { x / 0 })", "[]"},
+		{"filter([1, 2, 3, 4], fn(x) { x % 2 == 0 })", "[2, 4]"},
+		{"filter([1, 2, 3], fn(x) { if (x > 1) { x } })", "[2, 3]"},
+		{`filter(["", "a", false, 0], bool)`, "[, a, 0]"},
+		{"reduce([1, 2, 3], fn(acc, x) { acc + x })", "6"},
+		{"reduce([1, 2, 3], fn(acc, x) { acc + x }, 10)", "16"},
+		{"reduce([[1], [2, 3]], fn(acc, x) { acc + len(x) }, 0)", "3"},
+		{"map([1, 0, 2], fn(x) { 10 / x })", "division by zero"},
+		{"filter([1, 2], fn(x) { x + true })", "unknown operator: INTEGER + BOOLEAN"},
+		{"reduce([1, 2], fn(acc, x) { acc / 0 })", "division by zero"},
+		{`reduce([1, 2], fn(acc, x) { "s" })`, "s"},
+		{`map([1], 1)`, "map() fn must be a function, got=INTEGER"},
+		{`filter([1], "f")`, "filter() fn must be a function, got=STRING"},
+		{`reduce([1], [])`, "reduce() fn must be a function, got=ARRAY"},
+		{`filter(1, len)`, "filter() only supports arrays, got=INTEGER"},
+		{"map([1], fn(a, b) { a })", "wrong number of arguments: want=2, got=1"},
+		{"let f = fn(a, b) { a }; f(1)", "wrong number of arguments: want=2, got=1"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if errObj, ok := evaluated.(*object.Error); ok {
+			if errObj.Message != tt.expected {
+				t.Errorf("wrong error message for %s, expected=%q, got=%q", tt.input, tt.expected, errObj.Message)
+			}
+			continue
+		}
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("wrong result for %s, expected=%s, got=%s", tt.input, tt.expected, evaluated.Inspect())
+		}
+	}
+}
+
+func TestReduce(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`reduce(["a", "b", "c"], fn(acc, x) { acc + x })`, "abc"},
+		{`reduceRight(["a", "b", "c"], fn(acc, x) { acc + x })`, "cba"},
+		{`reduce([1, 2, 3], fn(acc, x) { push(acc, x * 2) }, [])`, "[2, 4, 6]"},
+		{`reduce([[1, 2], [3]], fn(acc, x) { push(acc, len(x)) }, [])`, "[2, 1]"},
+		{`reduce(["a", "b"], fn(acc, x, i) { acc[x] = i; acc }, {})`, "{a: 0, b: 1}"},
+		{`reduceRight([1, 2, 3], fn(acc, x, i) { push(acc, i) }, [])`, "[2, 1, 0]"},
+		{`reduce([1, 2], fn(acc, x, i, arr) { len(arr) }, 0)`, "2"},
+		{`reduce([1, 2, 3], max)`, "3"},
+		{`reduce([], fn(acc, x) { acc + x }, "init")`, "init"},
+		{`reduce([], fn(acc, x) { acc + x })`, "If reduce() hasn't received initial value, provided array musn't be empty"},
+		{`reduceRight([], fn(acc, x) { acc + x })`, "If reduceRight() hasn't received initial value, provided array musn't be empty"},
+		{`reduceRight([1, "a"], fn(acc, x) { acc - x })`, "unknown operator: STRING - INTEGER"},
+		{`reduceRight(1, len)`, "reduceRight() only supports arrays, got=INTEGER"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if errObj, ok := evaluated.(*object.Error); ok {
+			if errObj.Message != tt.expected {
+				t.Errorf("wrong error message for %s, expected=%q, got=%q", tt.input, tt.expected, errObj.Message)
+			}
+			continue
+		}
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("wrong result for %s, expected=%s, got=%s", tt.input, tt.expected, evaluated.Inspect())
+		}
+	}
+}
+
+func TestSlicing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"slice([1, 2, 3, 4], 1)", "[2, 3, 4]"},
+		{"slice([1, 2, 3, 4], 1, 3)", "[2, 3]"},
+		{"slice([1, 2, 3, 4], -2)", "[3, 4]"},
+		{"slice([1, 2, 3, 4], 0, -1)", "[1, 2, 3]"},
+		{"slice([1, 2, 3, 4], -10, 10)", "[1, 2, 3, 4]"},
+		{"slice([1, 2, 3, 4], 3, 1)", "[]"},
+		{"slice([1, 2, 3, 4], 5)", "[]"},
+		{`slice("héllo", 1, 3)`, "él"},
+		{`slice("hello", -3)`, "llo"},
+		{"[1, 2, 3, 4][1:3]", "[2, 3]"},
+		{"[1, 2, 3, 4][1:]", "[2, 3, 4]"},
+		{"[1, 2, 3, 4][:2]", "[1, 2]"},
+		{"[1, 2, 3, 4][:]", "[1, 2, 3, 4]"},
+		{"[1, 2, 3, 4][-3:-1]", "[2, 3]"},
+		{"[1, 2, 3, 4][2:100]", "[3, 4]"},
+		{"let i = 1; [1, 2, 3, 4][i + 1:]", "[3, 4]"},
+		{`"hello"[1:4]`, "ell"},
+		{`"hello"[-2:]`, "lo"},
+		{`"hello"[:-10]`, ""},
+		{"let a = [1, 2]; let b = a[:]; b[0] = 5; a", "[1, 2]"},
+		{"slice({}, 1)", "slice() only supports arrays and strings, got=HASH"},
+		{`slice([1], "a")`, "parameters 'begin' and 'end' of slice() must be integers!"},
+		{"5[1:2]", "Slice on INTEGER not supported"},
+		{`[1, 2][true:]`, "slice indices must be integers, got=BOOLEAN"},
+		{"[1, 2][x:]", "identifier not found: x"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if errObj, ok := evaluated.(*object.Error); ok {
+			if errObj.Message != tt.expected {
+				t.Errorf("wrong error message for %s, expected=%q, got=%q", tt.input, tt.expected, errObj.Message)
+			}
+			continue
+		}
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("wrong result for %s, expected=%s, got=%s", tt.input, tt.expected, evaluated.Inspect())
+		}
+	}
+}
+
+func TestErrorStackTrace(t *testing.T) {
+	tests := []struct {
+		input    string
+		message  string
+		expected []string
+	}{
+		{"1 + true", "unknown operator: INTEGER + BOOLEAN", nil},
+		{"let f = fn() { x }; f()", "identifier not found: x", []string{"f 1:21"}},
+		{
+			"let inner = fn() { x };\nlet outer = fn() { inner() };\nouter()",
+			"identifier not found: x",
+			[]string{"inner 2:20", "outer 3:1"},
+		},
+		{"fn() { x }()", "identifier not found: x", []string{"<anonymous> 1:1"}},
+		{"let g = fn() { x }; let h = g; h()", "identifier not found: x", []string{"g 1:32"}},
+		{
+			"let rec = fn(n) { if (n == 0) { x } else { rec(n - 1) } }; rec(2)",
+			"identifier not found: x",
+			[]string{"rec 1:44", "rec 1:44", "rec 1:60"},
+		},
+		{"map([1], fn(v) { v / 0 })", "division by zero", []string{"<anonymous> 1:1", "map 1:1"}},
+		{"len(1, 2)", "len() accepts single parameter, got=2", []string{"len 1:1"}},
+	}
+
+	for _, tt := range tests {
+		env := object.NewEnvironment()
+		evaluated := Eval(parser.New(lexer.New("", tt.input)).ParseProgram(), env)
+		if stack := evaluationOf(env).callStack; len(stack) != 0 {
+			t.Errorf("call stack not unwound for %s, got=%v", tt.input, stack)
+		}
+		if !testErrorObject(t, evaluated, tt.message) {
+			continue
+		}
+
+		frames := []string{}
+		for _, frame := range evaluated.(*object.Error).Stack {
+			frames = append(frames, fmt.Sprintf("%s %d:%d", frame.Function, frame.Position.Line, frame.Position.Column))
+		}
+		if strings.Join(frames, ", ") != strings.Join(tt.expected, ", ") {
+			t.Errorf("wrong stack for %s, expected=%v, got=%v", tt.input, tt.expected, frames)
+		}
+	}
+}
+
+func TestConcurrentStackTraces(t *testing.T) {
+	var wg sync.WaitGroup
+	stacks := make([]int, 8)
+
+	for i := range stacks {
+		wg.Add(1)
+		go func(depth int) {
+			defer wg.Done()
+			input := fmt.Sprintf("let rec = fn(n) { if (n == 0) { x } else { rec(n - 1) } }; rec(%d)", depth)
+			for j := 0; j < 50; j++ {
+				if errObj, ok := testEval(input).(*object.Error); ok {
+					stacks[depth] = len(errObj.Stack)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	for depth, frames := range stacks {
+		if frames != depth+1 {
+			t.Errorf("wrong stack depth for rec(%d), expected=%d, got=%d", depth, depth+1, frames)
+		}
+	}
+}
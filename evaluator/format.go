@@ -0,0 +1,122 @@
+package evaluator
+
+import (
+	"fmt"
+	"math/big"
+	"monkey/object"
+	"strings"
+	"unicode/utf8"
+)
+
+// formatObjects implements printf and format. Verbs follow Go's fmt package,
+// including flags, width and precision, applied to Monkey objects:
+//
+//	%v, %s  any object, the way puts shows it
+//	%q      a string, double-quoted and escaped
+//	%d      an integer, %b, %o, %x and %X in base 2, 8 and 16
+//	%e, %f, %g and their upper case forms: a number
+//	%t      a boolean
+//	%T      the type of any object
+//	%%      a percent sign
+//
+// Every verb consumes one argument, a verb of the wrong type, a missing or
+// an extra argument are errors.
+func formatObjects(name string, format string, args []object.Object) (string, *object.Error) {
+	var out strings.Builder
+	argIdx := 0
+
+	for i := 0; i < len(format); {
+		if format[i] != '%' {
+			next := strings.IndexByte(format[i:], '%')
+			if next < 0 {
+				next = len(format) - i
+			}
+			out.WriteString(format[i : i+next])
+			i += next
+			continue
+		}
+
+		// flags, width and precision are handed to fmt as they are
+		start := i
+		i++
+		for i < len(format) && strings.IndexByte("+-# 0", format[i]) >= 0 {
+			i++
+		}
+		for i < len(format) && (isDigit(format[i]) || format[i] == '.') {
+			i++
+		}
+		if i == len(format) {
+			return "", newError("%s() format ends in an incomplete verb %q", name, format[start:])
+		}
+		verb, size := utf8.DecodeRuneInString(format[i:])
+		spec := format[start:i] + string(verb)
+		i += size
+
+		if verb == '%' {
+			out.WriteByte('%')
+			continue
+		}
+		if argIdx == len(args) {
+			return "", newError("%s() is missing an argument for %s", name, spec)
+		}
+		arg := args[argIdx]
+		argIdx++
+
+		value, err := formatValue(name, verb, arg)
+		if err != nil {
+			return "", err
+		}
+		if verb == 'T' {
+			// the type name is already a string
+			spec = spec[:len(spec)-1] + "s"
+		}
+		fmt.Fprintf(&out, spec, value)
+	}
+
+	if argIdx < len(args) {
+		return "", newError("%s() got %d arguments, but the format uses %d",
+			name, len(args), argIdx)
+	}
+	return out.String(), nil
+}
+
+// formatValue converts arg to the Go value fmt expects for verb.
+func formatValue(name string, verb rune, arg object.Object) (interface{}, *object.Error) {
+	switch verb {
+	case 'v', 's':
+		return arg.Inspect(), nil
+	case 'T':
+		return string(arg.Type()), nil
+	case 'q':
+		if str, ok := arg.(*object.String); ok {
+			return str.Value, nil
+		}
+	case 'd', 'b', 'o', 'x', 'X':
+		switch arg := arg.(type) {
+		case *object.Integer:
+			return arg.Value, nil
+		case *object.BigInteger:
+			return arg.Value, nil
+		}
+	case 'e', 'E', 'f', 'F', 'g', 'G':
+		switch arg := arg.(type) {
+		case *object.Float:
+			return arg.Value, nil
+		case *object.Integer:
+			return float64(arg.Value), nil
+		case *object.BigInteger:
+			return new(big.Float).SetInt(arg.Value), nil
+		}
+	case 't':
+		if boolean, ok := arg.(*object.Boolean); ok {
+			return boolean.Value, nil
+		}
+	default:
+		return nil, newError("%s() has no verb %%%c", name, verb)
+	}
+	return nil, newError("%s() cannot format %s with %%%c", name, arg.Type(), verb)
+}
+
+func isDigit(ch byte) bool {
+	return '0' <= ch && ch <= '9'
+}
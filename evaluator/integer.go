@@ -0,0 +1,176 @@
+package evaluator
+
+import (
+	"math"
+	"math/big"
+	"monkey/object"
+)
+
+// OverflowPolicy decides what happens when the result of an integer
+// operation does not fit in an int64.
+type OverflowPolicy int
+
+const (
+	OverflowWrap    OverflowPolicy = iota // wrap around in two's complement, like Go does
+	OverflowError                         // evaluate to an error
+	OverflowPromote                       // continue with an arbitrary-precision integer
+)
+
+// integerArithmetic applies +, -, * or / (with a non-zero divisor) to two
+// integers, honouring the overflow policy.
+func integerArithmetic(operator string, left, right int64, policy OverflowPolicy) object.Object {
+	result, overflow := checkedArithmetic(operator, left, right)
+	if !overflow || policy == OverflowWrap {
+		return &object.Integer{Value: result}
+	}
+	return integerOverflow(operator, left, right, policy)
+}
+
+func checkedArithmetic(operator string, left, right int64) (int64, bool) {
+	switch operator {
+	case "+":
+		result := left + right
+		return result, (left^result)&(right^result) < 0
+	case "-":
+		result := left - right
+		return result, (left^right)&(left^result) < 0
+	case "*":
+		result := left * right
+		overflow := left != 0 &&
+			(result/left != right || (left == -1 && right == math.MinInt64))
+		return result, overflow
+	case "/":
+		return left / right, left == math.MinInt64 && right == -1
+	}
+	return 0, false
+}
+
+// integerPower raises base to a non-negative exponent by repeated squaring.
+// A negative exponent yields a float, 2 ** -1 is 0.5.
+func integerPower(base, exp int64, policy OverflowPolicy) object.Object {
+	if exp < 0 {
+		return &object.Float{Value: math.Pow(float64(base), float64(exp))}
+	}
+
+	result, overflow := int64(1), false
+	for b, e := base, exp; e > 0; {
+		var o bool
+		if e&1 == 1 {
+			result, o = checkedArithmetic("*", result, b)
+			overflow = overflow || o
+		}
+		e >>= 1
+		if e > 0 {
+			b, o = checkedArithmetic("*", b, b)
+			overflow = overflow || o
+		}
+	}
+
+	if !overflow || policy == OverflowWrap {
+		return &object.Integer{Value: result}
+	}
+	return integerOverflow("**", base, exp, policy)
+}
+
+func integerOverflow(operator string, left, right int64, policy OverflowPolicy) object.Object {
+	if policy == OverflowError {
+		return newError("integer overflow: %d %s %d", left, operator, right)
+	}
+
+	return evalBigIntegerInfixExpression(operator,
+		&object.BigInteger{Value: big.NewInt(left)},
+		&object.BigInteger{Value: big.NewInt(right)})
+}
+
+// maxBigIntBits bounds the size of big integer results of << and **, whose
+// memory use grows with the right operand.
+const maxBigIntBits = 1 << 24
+
+func isInteger(obj object.Object) bool {
+	return obj.Type() == object.INTEGER_OBJ || obj.Type() == object.BIG_INTEGER_OBJ
+}
+
+func toBigInt(obj object.Object) *big.Int {
+	switch obj := obj.(type) {
+	case *object.Integer:
+		return big.NewInt(obj.Value)
+	case *object.BigInteger:
+		return obj.Value
+	}
+	return nil
+}
+
+// evalBigIntegerInfixExpression handles integer operands of which at least
+// one is a big integer. The result of arithmetic stays a big integer.
+func evalBigIntegerInfixExpression(
+	operator string,
+	left, right object.Object,
+) object.Object {
+	leftVal := toBigInt(left)
+	rightVal := toBigInt(right)
+	result := new(big.Int)
+
+	switch operator {
+	case "+":
+		result.Add(leftVal, rightVal)
+	case "-":
+		result.Sub(leftVal, rightVal)
+	case "*":
+		result.Mul(leftVal, rightVal)
+	case "/":
+		if rightVal.Sign() == 0 {
+			return newError("division by zero")
+		}
+		result.Quo(leftVal, rightVal)
+	case "%":
+		if rightVal.Sign() == 0 {
+			return newError("modulo by zero")
+		}
+		result.Rem(leftVal, rightVal)
+	case "**":
+		if rightVal.Sign() < 0 {
+			return &object.Float{Value: math.Pow(toFloat(left), toFloat(right))}
+		}
+		if leftVal.CmpAbs(big.NewInt(1)) > 0 && (!rightVal.IsInt64() ||
+			rightVal.Int64() > maxBigIntBits/int64(leftVal.BitLen()-1)) {
+			return newError("exponent too large: %s", rightVal)
+		}
+		result.Exp(leftVal, rightVal, nil)
+	case "&":
+		result.And(leftVal, rightVal)
+	case "|":
+		result.Or(leftVal, rightVal)
+	case "^":
+		result.Xor(leftVal, rightVal)
+	case "<<", ">>":
+		if rightVal.Sign() < 0 {
+			return newError("negative shift count: %s", rightVal)
+		}
+		if operator == ">>" {
+			// shifting by more than the bit length leaves 0 or -1
+			n := uint(leftVal.BitLen()) + 1
+			if rightVal.IsInt64() && rightVal.Int64() < int64(n) {
+				n = uint(rightVal.Int64())
+			}
+			result.Rsh(leftVal, n)
+		} else {
+			if !rightVal.IsInt64() || rightVal.Int64() > maxBigIntBits {
+				return newError("shift count too large: %s", rightVal)
+			}
+			result.Lsh(leftVal, uint(rightVal.Int64()))
+		}
+	case "<":
+		return nativeBoolToBooleanObject(leftVal.Cmp(rightVal) < 0)
+	case ">":
+		return nativeBoolToBooleanObject(leftVal.Cmp(rightVal) > 0)
+	case "<=":
+		return nativeBoolToBooleanObject(leftVal.Cmp(rightVal) <= 0)
+	case ">=":
+		return nativeBoolToBooleanObject(leftVal.Cmp(rightVal) >= 0)
+	default:
+		return newError("unknown operator: %s %s %s",
+			left.Type(), operator, right.Type())
+	}
+
+	return &object.BigInteger{Value: result}
+}
@@ -0,0 +1,242 @@
+package evaluator
+
+import (
+	"math"
+	"math/big"
+	"monkey/object"
+)
+
+var abs = &object.BuiltinMethod{
+	Fn: func(env *object.Environment, args ...object.Object) object.Object {
+		number, err := singleNumber("abs", args)
+		if err != nil {
+			return err
+		}
+
+		switch number := number.(type) {
+		case *object.Integer:
+			if number.Value == math.MinInt64 {
+				// wrapping around would give a negative absolute value,
+				// so only promotion has a result to offer
+				if policy := evaluationOf(env).config.Overflow; policy == OverflowPromote {
+					return integerOverflow("-", 0, number.Value, policy)
+				}
+				return newError("integer overflow: abs(%d)", number.Value)
+			}
+			if number.Value < 0 {
+				return &object.Integer{Value: -number.Value}
+			}
+			return number
+		case *object.BigInteger:
+			return &object.BigInteger{Value: new(big.Int).Abs(number.Value)}
+		default:
+			return &object.Float{Value: math.Abs(toFloat(number))}
+		}
+	},
+}
+
+var minFn = &object.BuiltinMethod{
+	Fn: func(env *object.Environment, args ...object.Object) object.Object {
+		return extremum("min", args, -1)
+	},
+}
+
+var maxFn = &object.BuiltinMethod{
+	Fn: func(env *object.Environment, args ...object.Object) object.Object {
+		return extremum("max", args, 1)
+	},
+}
+
+// extremum returns the number for which compareNumbers against every other
+// one gives sign or 0. The numbers may be passed as arguments or as a single
+// array, a NaN among them is the result.
+func extremum(name string, args []object.Object, sign int) object.Object {
+	if len(args) == 1 {
+		if arr, ok := args[0].(*object.Array); ok {
+			args = arr.Elements
+		}
+	}
+	if len(args) == 0 {
+		return newError(`%s(...numbers) parameters are:
+				numbers: numbers to compare, or a single array of them,
+			`, name)
+	}
+
+	result := args[0]
+	for _, arg := range args {
+		if !isNumber(arg) {
+			return newError("%s() only supports numbers, got=%s", name, arg.Type())
+		}
+		if isNaNObject(arg) {
+			return arg
+		}
+		if compareNumbers(arg, result) == sign {
+			result = arg
+		}
+	}
+	return result
+}
+
+var pow = &object.BuiltinMethod{
+	Fn: func(env *object.Environment, args ...object.Object) object.Object {
+		if len(args) != 2 {
+			return newError(`pow(base, exponent) parameters are:
+				base: number to raise,
+				exponent: power to raise base to, the same as base ** exponent,
+			`)
+		}
+		for _, arg := range args {
+			if !isNumber(arg) {
+				return newError("pow() only supports numbers, got=%s", arg.Type())
+			}
+		}
+		return evalInfixExpression("**", args[0], args[1], evaluationOf(env).config.Overflow)
+	},
+}
+
+var sqrt = &object.BuiltinMethod{
+	Fn: func(env *object.Environment, args ...object.Object) object.Object {
+		number, err := singleNumber("sqrt", args)
+		if err != nil {
+			return err
+		}
+		return &object.Float{Value: math.Sqrt(toFloat(number))}
+	},
+}
+
+var floor = &object.BuiltinMethod{
+	Fn: func(env *object.Environment, args ...object.Object) object.Object {
+		return roundNumber("floor", args, math.Floor)
+	},
+}
+
+var ceil = &object.BuiltinMethod{
+	Fn: func(env *object.Environment, args ...object.Object) object.Object {
+		return roundNumber("ceil", args, math.Ceil)
+	},
+}
+
+var round = &object.BuiltinMethod{
+	Fn: func(env *object.Environment, args ...object.Object) object.Object {
+		return roundNumber("round", args, math.Round)
+	},
+}
+
+// roundNumber turns a float into an integer with fn, integers are returned
+// as they are.
+func roundNumber(name string, args []object.Object, fn func(float64) float64) object.Object {
+	number, err := singleNumber(name, args)
+	if err != nil {
+		return err
+	}
+
+	f, ok := number.(*object.Float)
+	if !ok {
+		return number
+	}
+	if math.IsNaN(f.Value) || math.IsInf(f.Value, 0) {
+		return newError("%s() cannot convert %s", name, f.Inspect())
+	}
+	return integerFromBig(bigFromFloat(fn(f.Value)))
+}
+
+var clamp = &object.BuiltinMethod{
+	Fn: func(env *object.Environment, args ...object.Object) object.Object {
+		if len(args) != 3 {
+			return newError(`clamp(value, min, max) parameters are:
+				value: number to limit,
+				min: smallest number returned,
+				max: largest number returned,
+			`)
+		}
+		for _, arg := range args {
+			if !isNumber(arg) {
+				return newError("clamp() only supports numbers, got=%s", arg.Type())
+			}
+		}
+
+		value, lo, hi := args[0], args[1], args[2]
+		if compareNumbers(lo, hi) > 0 {
+			return newError("clamp() min %s is greater than max %s", lo.Inspect(), hi.Inspect())
+		}
+		if compareNumbers(value, lo) < 0 {
+			return lo
+		}
+		if compareNumbers(value, hi) > 0 {
+			return hi
+		}
+		return value
+	},
+}
+
+var random = &object.BuiltinMethod{
+	Fn: func(env *object.Environment, args ...object.Object) object.Object {
+		if len(args) != 0 {
+			return newError("random() accepts no parameters, got=%d", len(args))
+		}
+		return &object.Float{Value: evaluationOf(env).random().Float64()}
+	},
+}
+
+var randomInt = &object.BuiltinMethod{
+	Fn: func(env *object.Environment, args ...object.Object) object.Object {
+		if len(args) < 1 || len(args) > 2 {
+			return newError(`randomInt(*min, max) parameters are:
+				min: (optional) smallest integer returned, 0 by default,
+				max: integer above the largest one returned,
+			`)
+		}
+		bounds := make([]int64, len(args))
+		for i, arg := range args {
+			integer, ok := arg.(*object.Integer)
+			if !ok {
+				return newError("randomInt() only supports integers, got=%s", arg.Type())
+			}
+			bounds[i] = integer.Value
+		}
+
+		lo, hi := int64(0), bounds[0]
+		if len(bounds) == 2 {
+			lo, hi = bounds[0], bounds[1]
+		}
+		if lo >= hi {
+			return newError("randomInt() range [%d, %d) is empty", lo, hi)
+		}
+		span, overflow := checkedArithmetic("-", hi, lo)
+		if overflow {
+			return newError("randomInt() range [%d, %d) is too large", lo, hi)
+		}
+		return &object.Integer{Value: lo + evaluationOf(env).random().Int63n(span)}
+	},
+}
+
+func singleNumber(name string, args []object.Object) (object.Object, *object.Error) {
+	if len(args) != 1 {
+		return nil, newError("%s() accepts single parameter, got=%d", name, len(args))
+	}
+	if !isNumber(args[0]) {
+		return nil, newError("%s() only supports numbers, got=%s", name, args[0].Type())
+	}
+	return args[0], nil
+}
+
+// compareNumbers returns -1, 0 or 1 as a is less than, equal to or greater
+// than b. Integers compare exactly, whatever their size.
+func compareNumbers(a, b object.Object) int {
+	if isInteger(a) && isInteger(b) {
+		return toBigInt(a).Cmp(toBigInt(b))
+	}
+	af, bf := toFloat(a), toFloat(b)
+	switch {
+	case af < bf:
+		return -1
+	case af > bf:
+		return 1
+	}
+	return 0
+}
+
+func isNaNObject(obj object.Object) bool {
+	f, ok := obj.(*object.Float)
+	return ok && math.IsNaN(f.Value)
+}
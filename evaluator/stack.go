@@ -0,0 +1,54 @@
+package evaluator
+
+import (
+	"monkey/ast"
+	"monkey/object"
+	"monkey/token"
+)
+
+// callFunction applies fn to args with a frame for the call pushed on the
+// stack of env's evaluation, attaching the stack trace to an error that
+// originates in the call.
+func callFunction(env *object.Environment, name string, fn object.Object, args []object.Object, pos token.Position) object.Object {
+	ev := evaluationOf(env)
+	ev.callStack = append(ev.callStack, object.StackFrame{Function: name, Position: pos})
+	result := invokeFunction(env, fn, args)
+
+	if errObj, ok := result.(*object.Error); ok && errObj.Stack == nil {
+		errObj.Stack = make([]object.StackFrame, len(ev.callStack))
+		for i, frame := range ev.callStack {
+			errObj.Stack[len(ev.callStack)-1-i] = frame
+		}
+	}
+	ev.callStack = ev.callStack[:len(ev.callStack)-1]
+
+	return result
+}
+
+// currentPosition is the call site of the innermost call in progress.
+func currentPosition(env *object.Environment) token.Position {
+	ev := evaluationOf(env)
+	if len(ev.callStack) == 0 {
+		return token.Position{}
+	}
+	return ev.callStack[len(ev.callStack)-1].Position
+}
+
+// functionName names fn for a stack frame. Functions bound with let carry
+// their name, builtins are named after the identifier they were called by.
+func functionName(fn object.Object, callee ast.Expression) string {
+	switch fn := fn.(type) {
+	case *object.Function:
+		if fn.Name != "" {
+			return fn.Name
+		}
+		return "<anonymous>"
+	case *object.BuiltinMethod:
+		if ident, ok := callee.(*ast.Identifier); ok {
+			return ident.Value
+		}
+		return "<builtin>"
+	default:
+		return "<anonymous>"
+	}
+}
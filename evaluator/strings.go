@@ -0,0 +1,329 @@
+package evaluator
+
+import (
+	"monkey/object"
+	"strings"
+	"unicode/utf8"
+)
+
+// String builtins count positions in characters (code points), the same
+// way len() and string indexing do.
+
+var split = &object.BuiltinMethod{
+	Fn: func(env *object.Environment, args ...object.Object) object.Object {
+		if len(args) != 2 {
+			return newError(`split(str, separator) parameters are:
+				str: string to split,
+				separator: string between the parts, "" splits into characters,
+			`)
+		}
+		str, sep, err := twoStrings("split", args)
+		if err != nil {
+			return err
+		}
+
+		parts := strings.Split(str, sep)
+		elements := make([]object.Object, len(parts))
+		for i, part := range parts {
+			elements[i] = &object.String{Value: part}
+		}
+		return &object.Array{Elements: elements}
+	},
+}
+
+var join = &object.BuiltinMethod{
+	Fn: func(env *object.Environment, args ...object.Object) object.Object {
+		if len(args) < 1 || len(args) > 2 {
+			return newError(`join(arr, *separator) parameters are:
+				arr: array whose elements are joined, non-strings are joined the way puts shows them,
+				separator: (optional) string put between the elements, "" by default,
+			`)
+		}
+		arr, ok := args[0].(*object.Array)
+		if !ok {
+			return newError("join() only supports arrays, got=%s", args[0].Type())
+		}
+		sep := ""
+		if len(args) == 2 {
+			str, ok := args[1].(*object.String)
+			if !ok {
+				return newError("join() separator must be a string, got=%s", args[1].Type())
+			}
+			sep = str.Value
+		}
+
+		parts := make([]string, len(arr.Elements))
+		for i, el := range arr.Elements {
+			parts[i] = el.Inspect()
+		}
+		return &object.String{Value: strings.Join(parts, sep)}
+	},
+}
+
+var trim = &object.BuiltinMethod{
+	Fn: func(env *object.Environment, args ...object.Object) object.Object {
+		if len(args) < 1 || len(args) > 2 {
+			return newError(`trim(str, *cutset) parameters are:
+				str: string to trim,
+				cutset: (optional) characters to remove from both ends, whitespace by default,
+			`)
+		}
+		str, ok := args[0].(*object.String)
+		if !ok {
+			return newError("trim() only supports strings, got=%s", args[0].Type())
+		}
+		if len(args) == 1 {
+			return &object.String{Value: strings.TrimSpace(str.Value)}
+		}
+		cutset, ok := args[1].(*object.String)
+		if !ok {
+			return newError("trim() cutset must be a string, got=%s", args[1].Type())
+		}
+		return &object.String{Value: strings.Trim(str.Value, cutset.Value)}
+	},
+}
+
+var upper = &object.BuiltinMethod{
+	Fn: func(env *object.Environment, args ...object.Object) object.Object {
+		str, err := singleString("upper", args)
+		if err != nil {
+			return err
+		}
+		return &object.String{Value: strings.ToUpper(str)}
+	},
+}
+
+var lower = &object.BuiltinMethod{
+	Fn: func(env *object.Environment, args ...object.Object) object.Object {
+		str, err := singleString("lower", args)
+		if err != nil {
+			return err
+		}
+		return &object.String{Value: strings.ToLower(str)}
+	},
+}
+
+var replace = &object.BuiltinMethod{
+	Fn: func(env *object.Environment, args ...object.Object) object.Object {
+		if len(args) < 3 || len(args) > 4 {
+			return newError(`replace(str, old, new, *count) parameters are:
+				str: string in which to replace,
+				old: substring to look for,
+				new: replacement for every occurrence of old,
+				count: (optional) number of occurrences to replace from the start, all by default,
+			`)
+		}
+		for i, arg := range args[:3] {
+			if arg.Type() != object.STRING_OBJ {
+				return newError("replace() parameter %d must be a string, got=%s", i+1, arg.Type())
+			}
+		}
+		count := int64(-1)
+		if len(args) == 4 {
+			n, ok := args[3].(*object.Integer)
+			if !ok {
+				return newError("replace() count must be an integer, got=%s", args[3].Type())
+			}
+			count = n.Value
+		}
+
+		str := args[0].(*object.String).Value
+		old := args[1].(*object.String).Value
+		replacement := args[2].(*object.String).Value
+		if count < 0 {
+			return &object.String{Value: strings.ReplaceAll(str, old, replacement)}
+		}
+		return &object.String{Value: strings.Replace(str, old, replacement, int(count))}
+	},
+}
+
+var contains = &object.BuiltinMethod{
+	Fn: func(env *object.Environment, args ...object.Object) object.Object {
+		if len(args) != 2 {
+			return newError(`contains(str, sub) parameters are:
+				str: string to search in,
+				sub: substring to look for,
+			`)
+		}
+		str, sub, err := twoStrings("contains", args)
+		if err != nil {
+			return err
+		}
+		return nativeBoolToBooleanObject(strings.Contains(str, sub))
+	},
+}
+
+var startsWith = &object.BuiltinMethod{
+	Fn: func(env *object.Environment, args ...object.Object) object.Object {
+		if len(args) != 2 {
+			return newError(`startsWith(str, prefix) parameters are:
+				str: string to test,
+				prefix: string str has to begin with,
+			`)
+		}
+		str, prefix, err := twoStrings("startsWith", args)
+		if err != nil {
+			return err
+		}
+		return nativeBoolToBooleanObject(strings.HasPrefix(str, prefix))
+	},
+}
+
+var endsWith = &object.BuiltinMethod{
+	Fn: func(env *object.Environment, args ...object.Object) object.Object {
+		if len(args) != 2 {
+			return newError(`endsWith(str, suffix) parameters are:
+				str: string to test,
+				suffix: string str has to end with,
+			`)
+		}
+		str, suffix, err := twoStrings("endsWith", args)
+		if err != nil {
+			return err
+		}
+		return nativeBoolToBooleanObject(strings.HasSuffix(str, suffix))
+	},
+}
+
+var indexOf = &object.BuiltinMethod{
+	Fn: func(env *object.Environment, args ...object.Object) object.Object {
+		if len(args) != 2 {
+			return newError(`indexOf(haystack, needle) parameters are:
+				haystack: string or array to search in,
+				needle: substring, or element equal to the one searched for,
+			`)
+		}
+
+		switch haystack := args[0].(type) {
+		case *object.String:
+			needle, ok := args[1].(*object.String)
+			if !ok {
+				return newError("indexOf() needle must be a string, got=%s", args[1].Type())
+			}
+			idx := strings.Index(haystack.Value, needle.Value)
+			if idx < 0 {
+				return &object.Integer{Value: -1}
+			}
+			return &object.Integer{Value: int64(utf8.RuneCountInString(haystack.Value[:idx]))}
+		case *object.Array:
+			for i, el := range haystack.Elements {
+				if object.Equal(el, args[1]) {
+					return &object.Integer{Value: int64(i)}
+				}
+			}
+			return &object.Integer{Value: -1}
+		default:
+			return newError("indexOf() only supports strings / arrays, got=%s", args[0].Type())
+		}
+	},
+}
+
+var repeat = &object.BuiltinMethod{
+	Fn: func(env *object.Environment, args ...object.Object) object.Object {
+		if len(args) != 2 {
+			return newError(`repeat(str, count) parameters are:
+				str: string to repeat,
+				count: number of copies, not negative,
+			`)
+		}
+		str, ok := args[0].(*object.String)
+		if !ok {
+			return newError("repeat() only supports strings, got=%s", args[0].Type())
+		}
+		count, ok := args[1].(*object.Integer)
+		if !ok {
+			return newError("repeat() count must be an integer, got=%s", args[1].Type())
+		}
+		return evalStringRepetition(str, count)
+	},
+}
+
+var padLeft = &object.BuiltinMethod{
+	Fn: func(env *object.Environment, args ...object.Object) object.Object {
+		return pad("padLeft", args, true)
+	},
+}
+
+var padRight = &object.BuiltinMethod{
+	Fn: func(env *object.Environment, args ...object.Object) object.Object {
+		return pad("padRight", args, false)
+	},
+}
+
+// pad extends str to length characters with copies of the pad string, the
+// last copy cut short if needed. Longer strings are left as they are.
+func pad(name string, args []object.Object, left bool) object.Object {
+	if len(args) < 2 || len(args) > 3 {
+		return newError(`%s(str, length, *pad) parameters are:
+				str: string to pad,
+				length: length in characters of the result,
+				pad: (optional) string to pad with, a space by default,
+			`, name)
+	}
+	str, ok := args[0].(*object.String)
+	if !ok {
+		return newError("%s() only supports strings, got=%s", name, args[0].Type())
+	}
+	length, ok := args[1].(*object.Integer)
+	if !ok {
+		return newError("%s() length must be an integer, got=%s", name, args[1].Type())
+	}
+	padding := " "
+	if len(args) == 3 {
+		padStr, ok := args[2].(*object.String)
+		if !ok {
+			return newError("%s() pad must be a string, got=%s", name, args[2].Type())
+		}
+		padding = padStr.Value
+	}
+
+	runeCount := int64(utf8.RuneCountInString(str.Value))
+	if length.Value <= runeCount || padding == "" {
+		return str
+	}
+	missing := length.Value - runeCount
+	padRunes := []rune(padding)
+	copies := missing/int64(len(padRunes)) + 1
+	if copies > int64(maxRepeatLength/len(padding)) {
+		return newError("%s() result too long: %d characters", name, length.Value)
+	}
+	fill := string([]rune(strings.Repeat(padding, int(copies)))[:missing])
+
+	if left {
+		return &object.String{Value: fill + str.Value}
+	}
+	return &object.String{Value: str.Value + fill}
+}
+
+func singleString(name string, args []object.Object) (string, *object.Error) {
+	if len(args) != 1 {
+		return "", newError("%s() accepts single parameter, got=%d", name, len(args))
+	}
+	str, ok := args[0].(*object.String)
+	if !ok {
+		return "", newError("%s() only supports strings, got=%s", name, args[0].Type())
+	}
+	return str.Value, nil
+}
+
+func twoStrings(name string, args []object.Object) (string, string, *object.Error) {
+	first, ok := args[0].(*object.String)
+	if !ok {
+		return "", "", newError("%s() only supports strings, got=%s", name, args[0].Type())
+	}
+	second, ok := args[1].(*object.String)
+	if !ok {
+		return "", "", newError("%s() second parameter must be a string, got=%s", name, args[1].Type())
+	}
+	return first.Value, second.Value, nil
+}
+
+func evalStringIndexExpression(str, idx object.Object) object.Object {
+	runes := []rune(str.(*object.String).Value)
+	index, ok := resolveIndex(idx.(*object.Integer).Value, len(runes))
+	if !ok {
+		return NULL
+	}
+
+	return &object.String{Value: string(runes[index])}
+}
@@ -1,29 +1,80 @@
 package lexer
 
 import (
+	"bufio"
+	"fmt"
+	"io"
 	"monkey/token"
+	"strings"
+	"unicode"
+	"unicode/utf8"
 )
 
+// readBufferSize bounds how much of a reader is held in memory at once when
+// the reader does not buffer itself.
+const readBufferSize = 4096
+
+// char is a decoded character together with its width in bytes, a zero
+// width marks the end of input.
+type char struct {
+	ch    rune
+	width int
+}
+
 type Lexer struct {
-	input        string
+	filename     string
+	reader       io.RuneReader
+	err          error
+	peeked       []char // characters read ahead by peekCharN
 	position     int
 	readPosition int
-	ch           byte
+	ch           rune
+	eof          bool
+	line         int
+	column       int
+	keepComments bool
+	recording    bool
+	lexeme       strings.Builder
 }
 
-// TODO: add support for filenames, line number + column number and for unicode ( right now ASCII ) and emojis!
+// New creates a lexer over input; filename is only used to annotate token
+// positions and may be empty.
+func New(filename, input string) *Lexer {
+	return NewReader(filename, strings.NewReader(input))
+}
 
-func New(input string) *Lexer {
-	l := &Lexer{input: input}
+// NewReader creates a lexer which reads its input incrementally from r,
+// producing the same tokens as New would for the whole input.
+func NewReader(filename string, r io.Reader) *Lexer {
+	rr, ok := r.(io.RuneReader)
+	if !ok {
+		rr = bufio.NewReaderSize(r, readBufferSize)
+	}
+	l := &Lexer{filename: filename, reader: rr, line: 1}
 	l.readChar()
 	return l
 }
 
-// TODO: abstract away behaviour of making 2byte literals into makeTwoCharToken() fn
+// KeepComments makes the lexer emit COMMENT tokens instead of skipping
+// comments, e.g. for a formatter that needs to preserve them.
+func (l *Lexer) KeepComments() {
+	l.keepComments = true
+}
+
 func (l *Lexer) NextToken() token.Token {
 	var tok token.Token
 
 	l.skipWhitespace()
+	for l.ch == '/' && (l.peekChar() == '/' || l.peekChar() == '*') {
+		start := l.pos()
+		comment := l.readComment()
+		if l.keepComments || comment.Type == token.ERROR {
+			return l.locate(comment, start)
+		}
+		l.skipWhitespace()
+	}
+
+	start := l.pos()
 
 	switch l.ch {
 	case ':':
@@ -34,19 +85,13 @@ func (l *Lexer) NextToken() token.Token {
 		tok = newToken(token.RBRACKET, l.ch)
 	case '=':
 		if l.peekChar() == '=' {
-			ch := l.ch
-			l.readChar()
-			literal := string(ch) + string(l.ch)
-			tok = token.Token{Type: token.EQ, Literal: literal}
+			tok = l.makeTwoCharToken(token.EQ)
 		} else {
 			tok = newToken(token.ASSIGN, l.ch)
 		}
 	case '!':
 		if l.peekChar() == '=' {
-			ch := l.ch
-			l.readChar()
-			literal := string(ch) + string(l.ch)
-			tok = token.Token{Type: token.NOT_EQ, Literal: literal}
+			tok = l.makeTwoCharToken(token.NOT_EQ)
 		} else {
 			tok = newToken(token.BANG, l.ch)
 		}
@@ -59,77 +104,249 @@ func (l *Lexer) NextToken() token.Token {
 	case ',':
 		tok = newToken(token.COMMA, l.ch)
 	case '+':
-		tok = newToken(token.PLUS, l.ch)
+		if l.peekChar() == '=' {
+			tok = l.makeTwoCharToken(token.PLUS_ASSIGN)
+		} else {
+			tok = newToken(token.PLUS, l.ch)
+		}
 	case '-':
-		tok = newToken(token.MINUS, l.ch)
+		if l.peekChar() == '=' {
+			tok = l.makeTwoCharToken(token.MINUS_ASSIGN)
+		} else {
+			tok = newToken(token.MINUS, l.ch)
+		}
 	case '*':
-		tok = newToken(token.ASTERISK, l.ch)
+		if l.peekChar() == '=' {
+			tok = l.makeTwoCharToken(token.ASTERISK_ASSIGN)
+		} else if l.peekChar() == '*' {
+			tok = l.makeTwoCharToken(token.POWER)
+		} else {
+			tok = newToken(token.ASTERISK, l.ch)
+		}
 	case '/':
-		tok = newToken(token.SLASH, l.ch)
+		if l.peekChar() == '=' {
+			tok = l.makeTwoCharToken(token.SLASH_ASSIGN)
+		} else {
+			tok = newToken(token.SLASH, l.ch)
+		}
 	case '<':
-		tok = newToken(token.LT, l.ch)
+		if l.peekChar() == '=' {
+			tok = l.makeTwoCharToken(token.LT_EQ)
+		} else if l.peekChar() == '<' {
+			tok = l.makeTwoCharToken(token.SHIFT_LEFT)
+		} else {
+			tok = newToken(token.LT, l.ch)
+		}
 	case '>':
-		tok = newToken(token.GT, l.ch)
+		if l.peekChar() == '=' {
+			tok = l.makeTwoCharToken(token.GT_EQ)
+		} else if l.peekChar() == '>' {
+			tok = l.makeTwoCharToken(token.SHIFT_RIGHT)
+		} else {
+			tok = newToken(token.GT, l.ch)
+		}
+	case '&':
+		if l.peekChar() == '&' {
+			tok = l.makeTwoCharToken(token.AND)
+		} else {
+			tok = newToken(token.AMPERSAND, l.ch)
+		}
+	case '|':
+		if l.peekChar() == '|' {
+			tok = l.makeTwoCharToken(token.OR)
+		} else {
+			tok = newToken(token.PIPE, l.ch)
+		}
+	case '^':
+		tok = newToken(token.CARET, l.ch)
+	case '~':
+		tok = newToken(token.TILDE, l.ch)
+	case '%':
+		tok = newToken(token.PERCENT, l.ch)
 	case '{':
 		tok = newToken(token.LBRACE, l.ch)
 	case '}':
 		tok = newToken(token.RBRACE, l.ch)
 	case 0:
-		tok.Literal = ""
-		tok.Type = token.EOF
+		if l.err != nil {
+			tok = token.Token{Type: token.ERROR, Literal: l.err.Error()}
+			l.err = nil
+		} else {
+			tok.Literal = ""
+			tok.Type = token.EOF
+		}
 	case '"':
-		tok.Type = token.STRING
-		tok.Literal = l.readString()
+		return l.locate(l.readString(), start)
+	case '`':
+		return l.locate(l.readRawString(), start)
 	default:
 		if isLetter(l.ch) {
 			tok.Literal = l.readIdentifier()
 			tok.Type = token.LookupIdent(tok.Literal)
-			return tok
+			return l.locate(tok, start)
 		} else if isDigit(l.ch) {
-			tok.Type = token.INT
-			tok.Literal = l.readNumber()
-			return tok
+			tok.Type, tok.Literal = l.readNumber()
+			return l.locate(tok, start)
 		} else {
 			tok = newToken(token.ILLEGAL, l.ch)
 		}
 	}
 	l.readChar()
-	return tok
+	return l.locate(tok, start)
 }
 
 func (l *Lexer) readChar() {
-	if l.readPosition >= len(l.input) {
-		l.ch = 0
+	if l.eof {
+		return
+	}
+	if l.recording {
+		l.lexeme.WriteRune(l.ch)
+	}
+	if l.ch == '\n' {
+		l.line += 1
+		l.column = 0
+	}
+
+	var next char
+	if len(l.peeked) > 0 {
+		next = l.peeked[0]
+		l.peeked = l.peeked[1:]
 	} else {
-		l.ch = l.input[l.readPosition]
+		next = l.fetch()
 	}
+
 	l.position = l.readPosition
-	l.readPosition += 1
+	l.readPosition += next.width
+	l.ch = next.ch
+	l.eof = next.width == 0
+	l.column += 1
+}
+
+// fetch decodes the next character from the reader. Read errors other than
+// io.EOF end the input and are reported once by NextToken.
+func (l *Lexer) fetch() char {
+	r, width, err := l.reader.ReadRune()
+	if err != nil {
+		if err != io.EOF && l.err == nil {
+			l.err = err
+		}
+		return char{}
+	}
+	return char{ch: r, width: width}
+}
+
+// startLexeme records every character consumed from now on, beginning with
+// the current one, until endLexeme is called.
+func (l *Lexer) startLexeme() {
+	l.lexeme.Reset()
+	l.recording = true
+}
+
+func (l *Lexer) endLexeme() string {
+	l.recording = false
+	return l.lexeme.String()
+}
+
+// pos returns the position of the current character.
+func (l *Lexer) pos() token.Position {
+	return token.Position{
+		Filename: l.filename,
+		Offset:   l.position,
+		Line:     l.line,
+		Column:   l.column,
+	}
 }
 
-func (l *Lexer) peekChar() byte {
-	if l.readPosition >= len(l.input) {
+func (l *Lexer) locate(tok token.Token, start token.Position) token.Token {
+	tok.Start = start
+	tok.End = l.pos()
+	return tok
+}
+
+func (l *Lexer) peekChar() rune {
+	return l.peekCharN(1)
+}
+
+// peekCharN returns the n-th character after the current one without
+// consuming anything.
+func (l *Lexer) peekCharN(n int) rune {
+	if l.eof {
 		return 0
-	} else {
-		return l.input[l.readPosition]
 	}
+	for len(l.peeked) < n {
+		l.peeked = append(l.peeked, l.fetch())
+	}
+	return l.peeked[n-1].ch
 }
 
-// TODO: add float, hex/bin/octal notation
-func (l *Lexer) readNumber() string {
-	position := l.position
-	for isDigit(l.ch) {
+// readNumber reads integer literals in decimal, hexadecimal (0x), octal (0o)
+// and binary (0b) notation as well as decimal floats; underscores may be used
+// as digit separators. Malformed digits are kept in the literal so that the
+// parser can report them.
+func (l *Lexer) readNumber() (token.TokenType, string) {
+	l.startLexeme()
+	var tokenType token.TokenType = token.INT
+
+	if l.ch == '0' && isBasePrefix(l.peekChar()) {
+		l.readChar()
+		l.readChar()
+		for isHexDigit(l.ch) || l.ch == '_' {
+			l.readChar()
+		}
+		return l.readBigIntSuffix(tokenType), l.endLexeme()
+	}
+
+	l.readDigits()
+
+	if l.ch == '.' && isDigit(l.peekChar()) {
+		tokenType = token.FLOAT
+		l.readChar()
+		l.readDigits()
+	}
+
+	if l.ch == 'e' || l.ch == 'E' {
+		next := l.peekChar()
+		if next == '+' || next == '-' {
+			next = l.peekCharN(2)
+		}
+		if isDigit(next) {
+			tokenType = token.FLOAT
+			l.readChar()
+			if l.ch == '+' || l.ch == '-' {
+				l.readChar()
+			}
+			l.readDigits()
+		}
+	}
+
+	return l.readBigIntSuffix(tokenType), l.endLexeme()
+}
+
+// readBigIntSuffix turns an integer into a big integer literal when it is
+// directly followed by a standalone n.
+func (l *Lexer) readBigIntSuffix(tokenType token.TokenType) token.TokenType {
+	if tokenType != token.INT || l.ch != 'n' {
+		return tokenType
+	}
+	if next := l.peekChar(); isLetter(next) || isDigit(next) {
+		return tokenType
+	}
+	l.readChar()
+	return token.BIGINT
+}
+
+func (l *Lexer) readDigits() {
+	for isDigit(l.ch) || l.ch == '_' {
 		l.readChar()
 	}
-	return l.input[position:l.position]
 }
 
 func (l *Lexer) readIdentifier() string {
-	position := l.position
+	l.startLexeme()
 	for isLetter(l.ch) {
 		l.readChar()
 	}
-	return l.input[position:l.position]
+	return l.endLexeme()
 }
 
 func (l *Lexer) skipWhitespace() {
@@ -138,25 +355,180 @@ func (l *Lexer) skipWhitespace() {
 	}
 }
 
-func (l *Lexer) readString() string {
-	position := l.position + 1
+// readComment reads a // line comment or a /* */ block comment, block
+// comments may be nested.
+func (l *Lexer) readComment() token.Token {
+	l.startLexeme()
+
+	if l.peekChar() == '/' {
+		for l.ch != '\n' && l.ch != 0 {
+			l.readChar()
+		}
+		return token.Token{Type: token.COMMENT, Literal: l.endLexeme()}
+	}
+
+	l.readChar()
+	l.readChar()
+	for depth := 1; depth > 0; {
+		switch {
+		case l.ch == 0:
+			l.endLexeme()
+			return token.Token{Type: token.ERROR, Literal: "unterminated block comment"}
+		case l.ch == '/' && l.peekChar() == '*':
+			depth++
+			l.readChar()
+		case l.ch == '*' && l.peekChar() == '/':
+			depth--
+			l.readChar()
+		}
+		l.readChar()
+	}
+	return token.Token{Type: token.COMMENT, Literal: l.endLexeme()}
+}
+
+// readString reads a double quoted string, resolving escape sequences. It
+// returns an ERROR token when the literal is malformed or not closed before
+// the end of the line.
+func (l *Lexer) readString() token.Token {
+	var out strings.Builder
+	var errMsg string
+
 	for {
 		l.readChar()
-		if l.ch == '"' || l.ch == 0 {
-			break
+		switch l.ch {
+		case '"':
+			l.readChar()
+			if errMsg != "" {
+				return token.Token{Type: token.ERROR, Literal: errMsg}
+			}
+			return token.Token{Type: token.STRING, Literal: out.String()}
+		case 0, '\n':
+			return token.Token{Type: token.ERROR, Literal: "unterminated string literal"}
+		case '\\':
+			if msg := l.readEscape(&out); msg != "" && errMsg == "" {
+				errMsg = msg
+			}
+		default:
+			out.WriteRune(l.ch)
 		}
 	}
-	return l.input[position:l.position]
 }
 
-func isLetter(ch byte) bool {
-	return 'a' <= ch && ch <= 'z' || 'A' <= ch && ch <= 'Z' || ch == '_'
+// readEscape resolves the escape sequence starting at the current backslash
+// and returns an error message if it is not valid.
+func (l *Lexer) readEscape(out *strings.Builder) string {
+	switch l.peekChar() {
+	case 'n':
+		out.WriteByte('\n')
+	case 't':
+		out.WriteByte('\t')
+	case 'r':
+		out.WriteByte('\r')
+	case '"':
+		out.WriteByte('"')
+	case '\\':
+		out.WriteByte('\\')
+	case 'u':
+		l.readChar()
+		return l.readUnicodeEscape(out)
+	case 0, '\n':
+		return ""
+	default:
+		l.readChar()
+		return fmt.Sprintf("unknown escape sequence \\%c", l.ch)
+	}
+	l.readChar()
+	return ""
 }
 
-func isDigit(ch byte) bool {
+// readUnicodeEscape reads the {hex} part of a \u{...} escape, leaving the
+// lexer on the closing brace.
+func (l *Lexer) readUnicodeEscape(out *strings.Builder) string {
+	if l.peekChar() != '{' {
+		return "invalid unicode escape, expected \\u{...}"
+	}
+	l.readChar()
+
+	var value rune
+	digits := 0
+	for isHexDigit(l.peekChar()) {
+		l.readChar()
+		value = value<<4 | hexValue(l.ch)
+		digits++
+		if digits > 6 {
+			return "unicode escape has too many digits"
+		}
+	}
+
+	if l.peekChar() != '}' || digits == 0 {
+		return "invalid unicode escape, expected \\u{...}"
+	}
+	l.readChar()
+
+	if !utf8.ValidRune(value) {
+		return fmt.Sprintf("invalid unicode code point U+%X", value)
+	}
+	out.WriteRune(value)
+	return ""
+}
+
+// readRawString reads a backtick delimited string which may span multiple
+// lines and does not interpret escape sequences.
+func (l *Lexer) readRawString() token.Token {
+	l.readChar()
+	l.startLexeme()
+	for l.ch != '`' {
+		if l.ch == 0 {
+			l.endLexeme()
+			return token.Token{Type: token.ERROR, Literal: "unterminated raw string literal"}
+		}
+		l.readChar()
+	}
+	literal := l.endLexeme()
+	l.readChar()
+	return token.Token{Type: token.STRING, Literal: literal}
+}
+
+func isLetter(ch rune) bool {
+	return 'a' <= ch && ch <= 'z' || 'A' <= ch && ch <= 'Z' || ch == '_' ||
+		ch >= utf8.RuneSelf && unicode.IsLetter(ch)
+}
+
+func isDigit(ch rune) bool {
 	return '0' <= ch && ch <= '9'
 }
 
-func newToken(tokenType token.TokenType, ch byte) token.Token {
+func isHexDigit(ch rune) bool {
+	return isDigit(ch) || 'a' <= ch && ch <= 'f' || 'A' <= ch && ch <= 'F'
+}
+
+func hexValue(ch rune) rune {
+	switch {
+	case 'a' <= ch && ch <= 'f':
+		return ch - 'a' + 10
+	case 'A' <= ch && ch <= 'F':
+		return ch - 'A' + 10
+	default:
+		return ch - '0'
+	}
+}
+
+func isBasePrefix(ch rune) bool {
+	switch ch {
+	case 'x', 'X', 'o', 'O', 'b', 'B':
+		return true
+	}
+	return false
+}
+
+// makeTwoCharToken consumes the current and the next character as a single
+// token.
+func (l *Lexer) makeTwoCharToken(tokenType token.TokenType) token.Token {
+	ch := l.ch
+	l.readChar()
+	return token.Token{Type: tokenType, Literal: string(ch) + string(l.ch)}
+}
+
+func newToken(tokenType token.TokenType, ch rune) token.Token {
 	return token.Token{Type: tokenType, Literal: string(ch)}
 }
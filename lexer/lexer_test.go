@@ -1,8 +1,12 @@
 package lexer
 
 import (
+	"errors"
+	"io"
 	"monkey/token"
+	"strings"
 	"testing"
+	"testing/iotest"
 )
 
 func TestNextToken(t *testing.T) {
@@ -14,7 +18,7 @@ let add = fn(x, y) {
 };
 
 let result = add(five, ten);
-!-/*5;
+!-/ *5;
 5 < 10 > 5;
 
 if (5 < 10) {
@@ -124,7 +128,515 @@ if (5 < 10) {
 		{token.EOF, ""},
 	}
 
-	l := New(input)
+	l := New("", input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. Expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. Expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+	}
+}
+
+func TestTokenPositions(t *testing.T) {
+	input := "let x = 5;\n  \"ab\" == x"
+
+	pos := func(offset, line, column int) token.Position {
+		return token.Position{Filename: "main.mk", Offset: offset, Line: line, Column: column}
+	}
+
+	tests := []struct {
+		expectedType  token.TokenType
+		expectedStart token.Position
+		expectedEnd   token.Position
+	}{
+		{token.LET, pos(0, 1, 1), pos(3, 1, 4)},
+		{token.IDENT, pos(4, 1, 5), pos(5, 1, 6)},
+		{token.ASSIGN, pos(6, 1, 7), pos(7, 1, 8)},
+		{token.INT, pos(8, 1, 9), pos(9, 1, 10)},
+		{token.SEMICOLON, pos(9, 1, 10), pos(10, 1, 11)},
+		{token.STRING, pos(13, 2, 3), pos(17, 2, 7)},
+		{token.EQ, pos(18, 2, 8), pos(20, 2, 10)},
+		{token.IDENT, pos(21, 2, 11), pos(22, 2, 12)},
+		{token.EOF, pos(22, 2, 12), pos(22, 2, 12)},
+	}
+
+	l := New("main.mk", input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. Expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+
+		if tok.Start != tt.expectedStart {
+			t.Errorf("tests[%d] - start wrong. Expected=%+v, got=%+v", i, tt.expectedStart, tok.Start)
+		}
+
+		if tok.End != tt.expectedEnd {
+			t.Errorf("tests[%d] - end wrong. Expected=%+v, got=%+v", i, tt.expectedEnd, tok.End)
+		}
+	}
+}
+
+func TestUnicodeInput(t *testing.T) {
+	input := "let café = \"日本語 👋\";\nπ_ü + 😀"
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+		expectedLine    int
+		expectedColumn  int
+	}{
+		{token.LET, "let", 1, 1},
+		{token.IDENT, "café", 1, 5},
+		{token.ASSIGN, "=", 1, 10},
+		{token.STRING, "日本語 👋", 1, 12},
+		{token.SEMICOLON, ";", 1, 19},
+		{token.IDENT, "π_ü", 2, 1},
+		{token.PLUS, "+", 2, 5},
+		{token.ILLEGAL, "😀", 2, 7},
+		{token.EOF, "", 2, 8},
+	}
+
+	l := New("", input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. Expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. Expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+
+		if tok.Start.Line != tt.expectedLine || tok.Start.Column != tt.expectedColumn {
+			t.Errorf("tests[%d] - position wrong. Expected=%d:%d, got=%s",
+				i, tt.expectedLine, tt.expectedColumn, tok.Start)
+		}
+	}
+}
+
+func TestNumberLiterals(t *testing.T) {
+	input := `5 3.14 0.5 1e10 2.5E-3 7e+2 4e x.5`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.INT, "5"},
+		{token.FLOAT, "3.14"},
+		{token.FLOAT, "0.5"},
+		{token.FLOAT, "1e10"},
+		{token.FLOAT, "2.5E-3"},
+		{token.FLOAT, "7e+2"},
+		{token.INT, "4"},
+		{token.IDENT, "e"},
+		{token.IDENT, "x"},
+		{token.ILLEGAL, "."},
+		{token.INT, "5"},
+		{token.EOF, ""},
+	}
+
+	l := New("", input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. Expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. Expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+	}
+}
+
+func TestIntegerNotations(t *testing.T) {
+	input := `0xFF 0o755 0b1010 1_000_000 0XdeadBEEF 0b102 1_000.5 12n 0xffn 1.5n 3name`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.INT, "0xFF"},
+		{token.INT, "0o755"},
+		{token.INT, "0b1010"},
+		{token.INT, "1_000_000"},
+		{token.INT, "0XdeadBEEF"},
+		{token.INT, "0b102"},
+		{token.FLOAT, "1_000.5"},
+		{token.BIGINT, "12n"},
+		{token.BIGINT, "0xffn"},
+		{token.FLOAT, "1.5"},
+		{token.IDENT, "n"},
+		{token.INT, "3"},
+		{token.IDENT, "name"},
+		{token.EOF, ""},
+	}
+
+	l := New("", input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. Expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. Expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+	}
+}
+
+func TestStringLiterals(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{`"a\nb"`, token.STRING, "a\nb"},
+		{`"tab\there"`, token.STRING, "tab\there"},
+		{`"say \"hi\""`, token.STRING, `say "hi"`},
+		{`"back\\slash"`, token.STRING, `back\slash`},
+		{`"\u{48}\u{1F44B}"`, token.STRING, "H👋"},
+		{"`raw \\n \"string\"`", token.STRING, `raw \n "string"`},
+		{"`multi\nline`", token.STRING, "multi\nline"},
+		{`"unterminated`, token.ERROR, "unterminated string literal"},
+		{"\"broken\nline\"", token.ERROR, "unterminated string literal"},
+		{"`unterminated", token.ERROR, "unterminated raw string literal"},
+		{`"bad \q escape"`, token.ERROR, `unknown escape sequence \q`},
+		{`"\u{110000}"`, token.ERROR, "invalid unicode code point U+110000"},
+		{`"\u48"`, token.ERROR, `invalid unicode escape, expected \u{...}`},
+	}
+
+	for i, tt := range tests {
+		l := New("", tt.input)
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. Expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. Expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+	}
+}
+
+func TestRawStringPositions(t *testing.T) {
+	l := New("", "`a\nbc` x")
+
+	str := l.NextToken()
+	if str.Start.Line != 1 || str.Start.Column != 1 || str.End.Line != 2 || str.End.Column != 4 {
+		t.Errorf("raw string span wrong, got=%s-%s", str.Start, str.End)
+	}
+
+	ident := l.NextToken()
+	if ident.Type != token.IDENT || ident.Start.Line != 2 || ident.Start.Column != 5 {
+		t.Errorf("token after raw string wrong, got=%q at %s", ident.Literal, ident.Start)
+	}
+}
+
+func TestComments(t *testing.T) {
+	input := `// leading comment
+let x = 5; // trailing
+/* block
+   comment */ x /* nested /* inner */ still comment */ + 1
+a / b`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.LET, "let"},
+		{token.IDENT, "x"},
+		{token.ASSIGN, "="},
+		{token.INT, "5"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "x"},
+		{token.PLUS, "+"},
+		{token.INT, "1"},
+		{token.IDENT, "a"},
+		{token.SLASH, "/"},
+		{token.IDENT, "b"},
+		{token.EOF, ""},
+	}
+
+	l := New("", input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. Expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. Expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+	}
+}
+
+func TestKeepComments(t *testing.T) {
+	input := "x // note\n/* a /* b */ */y"
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+		expectedLine    int
+	}{
+		{token.IDENT, "x", 1},
+		{token.COMMENT, "// note", 1},
+		{token.COMMENT, "/* a /* b */ */", 2},
+		{token.IDENT, "y", 2},
+		{token.EOF, "", 2},
+	}
+
+	l := New("", input)
+	l.KeepComments()
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. Expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. Expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+
+		if tok.Start.Line != tt.expectedLine {
+			t.Errorf("tests[%d] - line wrong. Expected=%d, got=%d", i, tt.expectedLine, tok.Start.Line)
+		}
+	}
+}
+
+func TestUnterminatedBlockComment(t *testing.T) {
+	l := New("", "x /* open /* nested */")
+
+	l.NextToken()
+	tok := l.NextToken()
+
+	if tok.Type != token.ERROR || tok.Literal != "unterminated block comment" {
+		t.Fatalf("expected unterminated block comment error, got=%q (%q)", tok.Type, tok.Literal)
+	}
+	if tok.Start.Column != 3 {
+		t.Errorf("error column wrong. Expected=3, got=%d", tok.Start.Column)
+	}
+	if next := l.NextToken(); next.Type != token.EOF {
+		t.Errorf("expected EOF after error, got=%q", next.Type)
+	}
+}
+
+func TestNewReaderMatchesNew(t *testing.T) {
+	input := `let café = fn(x) { x * 2.5e1 }; // comment
+/* block */ "esc\t\u{1F44B}" ` + "`raw\nstring`" + ` 0xFF != 1_000;`
+
+	expected := New("main.mk", input)
+	// OneByteReader hides the io.RuneReader of strings.Reader so the lexer
+	// has to buffer the input itself
+	actual := NewReader("main.mk", iotest.OneByteReader(strings.NewReader(input)))
+
+	for i := 0; ; i++ {
+		want := expected.NextToken()
+		got := actual.NextToken()
+
+		if got != want {
+			t.Fatalf("tokens[%d] differ. Expected=%+v, got=%+v", i, want, got)
+		}
+		if want.Type == token.EOF {
+			break
+		}
+	}
+}
+
+func TestNewReaderError(t *testing.T) {
+	input := io.MultiReader(strings.NewReader("let x"), iotest.ErrReader(errors.New("disk on fire")))
+	l := NewReader("", input)
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.LET, "let"},
+		{token.IDENT, "x"},
+		{token.ERROR, "disk on fire"},
+		{token.EOF, ""},
+	}
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. Expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. Expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+	}
+}
+
+func TestAssignOperators(t *testing.T) {
+	input := `x = 1; x += 2; x -= 3; x *= 4; x /= 5; x == y`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.IDENT, "x"},
+		{token.ASSIGN, "="},
+		{token.INT, "1"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "x"},
+		{token.PLUS_ASSIGN, "+="},
+		{token.INT, "2"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "x"},
+		{token.MINUS_ASSIGN, "-="},
+		{token.INT, "3"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "x"},
+		{token.ASTERISK_ASSIGN, "*="},
+		{token.INT, "4"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "x"},
+		{token.SLASH_ASSIGN, "/="},
+		{token.INT, "5"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "x"},
+		{token.EQ, "=="},
+		{token.IDENT, "y"},
+		{token.EOF, ""},
+	}
+
+	l := New("", input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. Expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. Expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+	}
+}
+
+func TestLoopKeywords(t *testing.T) {
+	input := `while for in break continue interval`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.WHILE, "while"},
+		{token.FOR, "for"},
+		{token.IN, "in"},
+		{token.BREAK, "break"},
+		{token.CONTINUE, "continue"},
+		{token.IDENT, "interval"},
+		{token.EOF, ""},
+	}
+
+	l := New("", input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. Expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. Expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+	}
+}
+
+func TestLogicalAndComparisonOperators(t *testing.T) {
+	input := `a <= b >= c && d || e < f > g & |`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.IDENT, "a"},
+		{token.LT_EQ, "<="},
+		{token.IDENT, "b"},
+		{token.GT_EQ, ">="},
+		{token.IDENT, "c"},
+		{token.AND, "&&"},
+		{token.IDENT, "d"},
+		{token.OR, "||"},
+		{token.IDENT, "e"},
+		{token.LT, "<"},
+		{token.IDENT, "f"},
+		{token.GT, ">"},
+		{token.IDENT, "g"},
+		{token.AMPERSAND, "&"},
+		{token.PIPE, "|"},
+		{token.EOF, ""},
+	}
+
+	l := New("", input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. Expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. Expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+	}
+}
+
+func TestArithmeticAndBitwiseOperators(t *testing.T) {
+	input := `a % b ** c & d | e ^ ~f << g >> h *= i`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.IDENT, "a"},
+		{token.PERCENT, "%"},
+		{token.IDENT, "b"},
+		{token.POWER, "**"},
+		{token.IDENT, "c"},
+		{token.AMPERSAND, "&"},
+		{token.IDENT, "d"},
+		{token.PIPE, "|"},
+		{token.IDENT, "e"},
+		{token.CARET, "^"},
+		{token.TILDE, "~"},
+		{token.IDENT, "f"},
+		{token.SHIFT_LEFT, "<<"},
+		{token.IDENT, "g"},
+		{token.SHIFT_RIGHT, ">>"},
+		{token.IDENT, "h"},
+		{token.ASTERISK_ASSIGN, "*="},
+		{token.IDENT, "i"},
+		{token.EOF, ""},
+	}
+
+	l := New("", input)
 
 	for i, tt := range tests {
 		tok := l.NextToken()
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"fmt"
 	"monkey/repl"
 	"os"
 )
@@ -9,15 +10,30 @@ import (
 // TODO: handle postfix operators
 // TODO: record latest lines, conjure them up with upper arrow
 // TODO: rewrite to C, write own GC
-// TODO: add char espacing for strings: \n \t
-// TODO: type coercion
-// TODO: parseInt, parseFloat, isNan impl
-// TODO: support floats
 // TODO: prototypes
-// TODO: make reduce to accepts other types ( now only int, array) as an initial value
-// TODO: reassigments
-// TODO: loops
 
 func main() {
+	if len(os.Args) > 1 {
+		os.Exit(runFile(os.Args[1]))
+	}
 	repl.Start(os.Stdin, os.Stdout)
 }
+
+// runFile executes the script at path, "-" reads the script from stdin.
+func runFile(path string) int {
+	in, name := os.Stdin, "<stdin>"
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		defer f.Close()
+		in, name = f, path
+	}
+
+	if !repl.Run(name, in, os.Stdout, os.Stderr) {
+		return 1
+	}
+	return 0
+}
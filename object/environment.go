@@ -3,6 +3,7 @@ package object
 type Environment struct {
 	store map[string]Object
 	outer *Environment
+	host  interface{}
 }
 
 func NewEnvironment() *Environment {
@@ -23,6 +24,36 @@ func (e *Environment) Set(name string, val Object) Object {
 	return val
 }
 
+// Assign rebinds name in the innermost scope defining it, it reports false if
+// name is not defined anywhere.
+func (e *Environment) Assign(name string, val Object) (Object, bool) {
+	if _, ok := e.store[name]; ok {
+		e.store[name] = val
+		return val, true
+	}
+	if e.outer != nil {
+		return e.outer.Assign(name, val)
+	}
+	return nil, false
+}
+
+// Host returns the value attached to the outermost environment with SetHost.
+func (e *Environment) Host() interface{} {
+	for e.outer != nil {
+		e = e.outer
+	}
+	return e.host
+}
+
+// SetHost attaches host to the outermost environment, so that it is shared
+// by every scope enclosed in it. The evaluator keeps its per-run state there.
+func (e *Environment) SetHost(host interface{}) {
+	for e.outer != nil {
+		e = e.outer
+	}
+	e.host = host
+}
+
 func NewEnclosedEnvironment(outer *Environment) *Environment {
 	env := NewEnvironment()
 	env.outer = outer
@@ -0,0 +1,48 @@
+package object
+
+import "testing"
+
+func TestEnvironmentAssign(t *testing.T) {
+	outer := NewEnvironment()
+	outer.Set("counter", &Integer{Value: 1})
+
+	inner := NewEnclosedEnvironment(outer)
+	inner.Set("local", &Integer{Value: 2})
+
+	if _, ok := inner.Assign("counter", &Integer{Value: 10}); !ok {
+		t.Fatalf("assigning outer binding from inner scope failed")
+	}
+	if _, ok := inner.store["counter"]; ok {
+		t.Errorf("assignment should not create a binding in the inner scope")
+	}
+	if val, _ := outer.Get("counter"); val.(*Integer).Value != 10 {
+		t.Errorf("outer binding not updated, got=%s", val.Inspect())
+	}
+
+	if _, ok := inner.Assign("missing", &Integer{Value: 1}); ok {
+		t.Errorf("assigning an undeclared name should fail")
+	}
+	if _, ok := outer.Get("missing"); ok {
+		t.Errorf("failed assignment should not create a binding")
+	}
+}
+
+func TestEnvironmentHost(t *testing.T) {
+	outer := NewEnvironment()
+	inner := NewEnclosedEnvironment(NewEnclosedEnvironment(outer))
+
+	if inner.Host() != nil {
+		t.Fatalf("new environment has a host, got=%v", inner.Host())
+	}
+
+	inner.SetHost("state")
+	if outer.host != "state" {
+		t.Errorf("host not attached to the outermost environment, got=%v", outer.host)
+	}
+	if inner.host != nil {
+		t.Errorf("host attached to an inner environment")
+	}
+	if NewEnclosedEnvironment(outer).Host() != "state" {
+		t.Errorf("host not shared by enclosed environments")
+	}
+}
@@ -0,0 +1,120 @@
+package object
+
+import (
+	"math"
+	"math/big"
+)
+
+// Equal reports whether a and b are equal. It defines the == and != operators
+// and every builtin that looks for a value, so they all agree:
+//
+//   - numbers are equal when their values are, regardless of being INTEGER,
+//     BIG_INTEGER or FLOAT; NaN is not equal to anything, itself included
+//   - strings and booleans compare by value
+//   - arrays are equal when they have the same length and equal elements at
+//     every index
+//   - object literals are equal when they have the same keys and equal values
+//     for every key
+//   - anything else (null, functions, builtins) is only equal to itself
+//
+// Arrays and object literals may contain themselves. A pair of values met
+// again while it is still being compared is assumed equal, which makes two
+// cycles of the same shape equal instead of recursing forever.
+func Equal(a, b Object) bool {
+	return equal(a, b, map[[2]Object]bool{})
+}
+
+func equal(a, b Object, comparing map[[2]Object]bool) bool {
+	if isNumber(a) && isNumber(b) {
+		return numbersEqual(a, b)
+	}
+
+	switch a := a.(type) {
+	case *String:
+		b, ok := b.(*String)
+		return ok && a.Value == b.Value
+	case *Boolean:
+		b, ok := b.(*Boolean)
+		return ok && a.Value == b.Value
+	case *Array:
+		b, ok := b.(*Array)
+		if !ok || len(a.Elements) != len(b.Elements) {
+			return false
+		}
+		if a == b || comparing[[2]Object{a, b}] {
+			return true
+		}
+		comparing[[2]Object{a, b}] = true
+		defer delete(comparing, [2]Object{a, b})
+
+		for i, el := range a.Elements {
+			if !equal(el, b.Elements[i], comparing) {
+				return false
+			}
+		}
+		return true
+	case *ObjectLiteral:
+		b, ok := b.(*ObjectLiteral)
+		if !ok || len(a.Pairs) != len(b.Pairs) {
+			return false
+		}
+		if a == b || comparing[[2]Object{a, b}] {
+			return true
+		}
+		comparing[[2]Object{a, b}] = true
+		defer delete(comparing, [2]Object{a, b})
+
+		for key, pair := range a.Pairs {
+			other, ok := b.Pairs[key]
+			if !ok || !equal(pair.Value, other.Value, comparing) {
+				return false
+			}
+		}
+		return true
+	default:
+		return a == b
+	}
+}
+
+func isNumber(obj Object) bool {
+	switch obj.(type) {
+	case *Integer, *BigInteger, *Float:
+		return true
+	}
+	return false
+}
+
+func numbersEqual(a, b Object) bool {
+	if a, ok := a.(*Integer); ok {
+		if b, ok := b.(*Integer); ok {
+			return a.Value == b.Value
+		}
+	}
+	if ai, ok := bigValue(a); ok {
+		if bi, ok := bigValue(b); ok {
+			return ai.Cmp(bi) == 0
+		}
+	}
+
+	af, bf := bigFloatValue(a), bigFloatValue(b)
+	if af == nil || bf == nil {
+		return false
+	}
+	return af.Cmp(bf) == 0
+}
+
+// bigFloatValue converts a number exactly, it returns nil for NaN.
+func bigFloatValue(obj Object) *big.Float {
+	switch obj := obj.(type) {
+	case *Integer:
+		return new(big.Float).SetInt64(obj.Value)
+	case *BigInteger:
+		return new(big.Float).SetInt(obj.Value)
+	case *Float:
+		if math.IsNaN(obj.Value) {
+			return nil
+		}
+		return big.NewFloat(obj.Value)
+	}
+	return nil
+}
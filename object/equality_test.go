@@ -0,0 +1,63 @@
+package object
+
+import (
+	"math"
+	"math/big"
+	"testing"
+)
+
+func TestEqual(t *testing.T) {
+	huge, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	fn := &Function{}
+
+	tests := []struct {
+		a, b     Object
+		expected bool
+	}{
+		{&Integer{Value: 1}, &Integer{Value: 1}, true},
+		{&Integer{Value: 1}, &Float{Value: 1}, true},
+		{&BigInteger{Value: big.NewInt(1)}, &Float{Value: 1}, true},
+		{&BigInteger{Value: huge}, &BigInteger{Value: new(big.Int).Set(huge)}, true},
+		{&Integer{Value: 9007199254740993}, &Float{Value: 9007199254740992}, false},
+		{&Float{Value: math.NaN()}, &Float{Value: math.NaN()}, false},
+		{&Float{Value: math.Inf(1)}, &Float{Value: math.Inf(1)}, true},
+		{&String{Value: "a"}, &String{Value: "a"}, true},
+		{&String{Value: "1"}, &Integer{Value: 1}, false},
+		{&Boolean{Value: true}, &Boolean{Value: true}, true},
+		{fn, fn, true},
+		{fn, &Function{}, false},
+	}
+
+	for i, tt := range tests {
+		if got := Equal(tt.a, tt.b); got != tt.expected {
+			t.Errorf("tests[%d] - Equal(%s, %s) = %t, expected %t",
+				i, tt.a.Inspect(), tt.b.Inspect(), got, tt.expected)
+		}
+	}
+}
+
+func TestEqualCycles(t *testing.T) {
+	a := &Array{}
+	a.Elements = []Object{&Integer{Value: 1}, a}
+	b := &Array{}
+	b.Elements = []Object{&Integer{Value: 1}, b}
+	c := &Array{}
+	c.Elements = []Object{&Integer{Value: 2}, c}
+
+	if !Equal(a, b) {
+		t.Errorf("arrays containing themselves in the same shape should be equal")
+	}
+	if Equal(a, c) {
+		t.Errorf("arrays containing themselves with different elements should not be equal")
+	}
+
+	key := &String{Value: "self"}
+	o1 := &ObjectLiteral{Pairs: map[HashKey]HashPair{}}
+	o1.Pairs[key.HashKey()] = HashPair{Key: key, Value: o1}
+	o2 := &ObjectLiteral{Pairs: map[HashKey]HashPair{}}
+	o2.Pairs[key.HashKey()] = HashPair{Key: key, Value: o2}
+
+	if !Equal(o1, o2) {
+		t.Errorf("object literals containing themselves in the same shape should be equal")
+	}
+}
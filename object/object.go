@@ -4,15 +4,24 @@ import (
 	"hash/fnv"
 	"bytes"
 	"fmt"
+	"math"
+	"math/big"
 	"monkey/ast"
+	"monkey/token"
+	"sort"
+	"strconv"
 	"strings"
 )
 
 const (
 	INTEGER_OBJ      = "INTEGER"
+	BIG_INTEGER_OBJ  = "BIG_INTEGER"
+	FLOAT_OBJ        = "FLOAT"
 	BOOLEAN_OBJ      = "BOOLEAN"
 	NULL_OBJ         = "NULL"
 	RETURN_VALUE_OBJ = "RETURN_VALUE"
+	BREAK_OBJ        = "BREAK"
+	CONTINUE_OBJ     = "CONTINUE"
 	ERROR_OBJ        = "ERROR"
 	FUNCTION_OBJ     = "FUNCTION"
 	STRING_OBJ       = "STRING"
@@ -35,6 +44,27 @@ type Integer struct {
 func (i *Integer) Inspect() string  { return fmt.Sprintf("%d", i.Value) }
 func (i *Integer) Type() ObjectType { return INTEGER_OBJ }
 
+// BigInteger holds integers that do not fit in an int64.
+type BigInteger struct {
+	Value *big.Int
+}
+
+func (bi *BigInteger) Inspect() string  { return bi.Value.String() }
+func (bi *BigInteger) Type() ObjectType { return BIG_INTEGER_OBJ }
+
+type Float struct {
+	Value float64
+}
+
+func (f *Float) Inspect() string {
+	s := strconv.FormatFloat(f.Value, 'g', -1, 64)
+	if !strings.ContainsAny(s, ".eIN") {
+		s += ".0"
+	}
+	return s
+}
+func (f *Float) Type() ObjectType { return FLOAT_OBJ }
+
 type Boolean struct {
 	Value bool
 }
@@ -54,15 +84,59 @@ type ReturnValue struct {
 func (rv *ReturnValue) Type() ObjectType { return RETURN_VALUE_OBJ }
 func (rv *ReturnValue) Inspect() string  { return rv.Value.Inspect() }
 
-// TODO: implement stack tracing
+// Break and Continue travel up through block statements like ReturnValue
+// until the enclosing loop consumes them.
+type Break struct{}
+
+func (b *Break) Type() ObjectType { return BREAK_OBJ }
+func (b *Break) Inspect() string  { return "break" }
+
+type Continue struct{}
+
+func (c *Continue) Type() ObjectType { return CONTINUE_OBJ }
+func (c *Continue) Inspect() string  { return "continue" }
+
+// StackFrame is a single function call on the stack: the name of the called
+// function and the position of the call expression.
+type StackFrame struct {
+	Function string
+	Position token.Position
+}
+
 type Error struct {
 	Message string
+	Stack   []StackFrame // innermost call first, empty for top level errors
 }
 
 func (e *Error) Type() ObjectType { return ERROR_OBJ }
 func (e *Error) Inspect() string  { return "ERROR: " + e.Message }
 
+// StackTrace renders the error followed by the calls that led to it,
+// innermost first. Runs of identical frames, as left behind by deep
+// recursion, are collapsed into a single line.
+func (e *Error) StackTrace() string {
+	var out bytes.Buffer
+
+	out.WriteString(e.Inspect())
+	for i := 0; i < len(e.Stack); {
+		frame := e.Stack[i]
+		repeated := 1
+		for i+repeated < len(e.Stack) && e.Stack[i+repeated] == frame {
+			repeated++
+		}
+
+		out.WriteString(fmt.Sprintf("\n\tat %s (%s)", frame.Function, frame.Position))
+		if repeated > 1 {
+			out.WriteString(fmt.Sprintf("\n\t[previous frame repeated %d more times]", repeated-1))
+		}
+		i += repeated
+	}
+
+	return out.String()
+}
+
 type Function struct {
+	Name       string // set when a function literal is bound with let
 	Parameters []*ast.Identifier
 	Body       *ast.BlockStatement
 	Env        *Environment
@@ -94,7 +168,9 @@ type String struct {
 func (s *String) Type() ObjectType { return STRING_OBJ }
 func (s *String) Inspect() string  { return s.Value }
 
-type BuiltinFunction func(args ...Object) Object
+// BuiltinFunction gets the environment of the call along with the arguments,
+// which gives builtins calling back into the script access to the evaluation.
+type BuiltinFunction func(env *Environment, args ...Object) Object
 
 type BuiltinMethod struct {
 	Fn BuiltinFunction
@@ -144,6 +220,38 @@ func (i *Integer) HashKey() HashKey {
 	return HashKey{Type: i.Type(), Value: uint64(i.Value)}
 }
 
+// Big integers within the int64 range hash like the equal integer, so that 5
+// and 5n address the same object literal entry.
+func (bi *BigInteger) HashKey() HashKey {
+	return bigIntHashKey(bi.Value)
+}
+
+func bigIntHashKey(value *big.Int) HashKey {
+	if value.IsInt64() {
+		return HashKey{Type: INTEGER_OBJ, Value: uint64(value.Int64())}
+	}
+	h := fnv.New64a()
+	h.Write([]byte(value.String()))
+	return HashKey{Type: BIG_INTEGER_OBJ, Value: h.Sum64()}
+}
+
+// Floats holding a whole number hash like the equal integer or big integer,
+// so that 1 and 1.0, or 1e19 and 10000000000000000000n, address the same
+// object literal entry.
+func (f *Float) HashKey() HashKey {
+	if f.Value == math.Trunc(f.Value) && f.Value >= -1<<63 && f.Value < 1<<63 {
+		return HashKey{Type: INTEGER_OBJ, Value: uint64(int64(f.Value))}
+	}
+	if f.Value == math.Trunc(f.Value) && !math.IsInf(f.Value, 0) {
+		value, _ := big.NewFloat(f.Value).Int(nil)
+		return bigIntHashKey(value)
+	}
+	if math.IsNaN(f.Value) {
+		return HashKey{Type: f.Type(), Value: math.Float64bits(math.NaN())}
+	}
+	return HashKey{Type: f.Type(), Value: math.Float64bits(f.Value)}
+}
+
 // todo: CACHE RESULTS
 func (s *String) HashKey() HashKey {
 	h := fnv.New64a()
@@ -165,7 +273,7 @@ func (ol *ObjectLiteral) Inspect() string {
 	var out bytes.Buffer
 
 	pairs := []string{}
-	for _, pair := range ol.Pairs {
+	for _, pair := range ol.SortedPairs() {
 		pairs = append(pairs, fmt.Sprintf("%s: %s",
 			pair.Key.Inspect(), pair.Value.Inspect()))
 	}
@@ -177,6 +285,75 @@ func (ol *ObjectLiteral) Inspect() string {
 	return out.String()
 }
 
+// SortedPairs returns the pairs ordered by key: booleans first, then numbers
+// by value, then strings. Iteration over Pairs itself is random.
+func (ol *ObjectLiteral) SortedPairs() []HashPair {
+	pairs := make([]HashPair, 0, len(ol.Pairs))
+	for _, pair := range ol.Pairs {
+		pairs = append(pairs, pair)
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		return keyLess(pairs[i].Key, pairs[j].Key)
+	})
+	return pairs
+}
+
+func keyRank(key Object) int {
+	switch key.(type) {
+	case *Boolean:
+		return 0
+	case *Integer, *BigInteger, *Float:
+		return 1
+	default:
+		return 2
+	}
+}
+
+func keyLess(a, b Object) bool {
+	if ra, rb := keyRank(a), keyRank(b); ra != rb {
+		return ra < rb
+	}
+	if ai, ok := bigValue(a); ok {
+		if bi, ok := bigValue(b); ok {
+			return ai.Cmp(bi) < 0
+		}
+	}
+	switch a := a.(type) {
+	case *Boolean:
+		return !a.Value && b.(*Boolean).Value
+	case *Integer, *BigInteger, *Float:
+		return numberValue(a) < numberValue(b)
+	case *String:
+		if b, ok := b.(*String); ok {
+			return a.Value < b.Value
+		}
+	}
+	return false
+}
+
+func bigValue(obj Object) (*big.Int, bool) {
+	switch obj := obj.(type) {
+	case *Integer:
+		return big.NewInt(obj.Value), true
+	case *BigInteger:
+		return obj.Value, true
+	}
+	return nil, false
+}
+
+func numberValue(obj Object) float64 {
+	switch obj := obj.(type) {
+	case *Integer:
+		return float64(obj.Value)
+	case *BigInteger:
+		f, _ := new(big.Float).SetInt(obj.Value).Float64()
+		return f
+	case *Float:
+		return obj.Value
+	}
+	return 0
+}
+
 type Hashable interface {
 	HashKey() HashKey
 }
\ No newline at end of file
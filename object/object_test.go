@@ -1,6 +1,11 @@
 package object
 
-import "testing"
+import (
+	"math"
+	"math/big"
+	"monkey/token"
+	"testing"
+)
 
 func TestStringHashKey(t *testing.T) {
 	hello1 := &String{Value: "Hello world"}
@@ -72,4 +77,82 @@ func TestBoolHashKey(t *testing.T) {
 	if bool2.HashKey() == otherBool2.HashKey() {
 		t.Errorf("not equal bools should not have equal hashes!")		
 	}	
-}
\ No newline at end of file
+}
+func TestFloatHashKey(t *testing.T) {
+	float1 := &Float{Value: 1.5}
+	float2 := &Float{Value: 1.5}
+
+	otherFloat := &Float{Value: 2.5}
+	wholeFloat := &Float{Value: 2}
+
+	if float1.HashKey() != float2.HashKey() {
+		t.Errorf("equal floats should have equal hashes!")
+	}
+
+	if float1.HashKey() == otherFloat.HashKey() {
+		t.Errorf("not equal floats should not have equal hashes!")
+	}
+
+	if wholeFloat.HashKey() != (&Integer{Value: 2}).HashKey() {
+		t.Errorf("whole floats should hash like the equal integer!")
+	}
+
+	if (&Float{Value: -1 << 63}).HashKey() != (&Integer{Value: math.MinInt64}).HashKey() {
+		t.Errorf("-2**63 should hash like the smallest integer!")
+	}
+}
+
+func TestBigIntegerHashKey(t *testing.T) {
+	huge1, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	huge2, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+
+	if (&BigInteger{Value: huge1}).HashKey() != (&BigInteger{Value: huge2}).HashKey() {
+		t.Errorf("equal big integers should have equal hashes!")
+	}
+
+	if (&BigInteger{Value: huge1}).HashKey() == (&BigInteger{Value: big.NewInt(1)}).HashKey() {
+		t.Errorf("not equal big integers should not have equal hashes!")
+	}
+
+	if (&BigInteger{Value: big.NewInt(-7)}).HashKey() != (&Integer{Value: -7}).HashKey() {
+		t.Errorf("small big integers should hash like the equal integer!")
+	}
+
+	huge3, _ := new(big.Int).SetString("10000000000000000000", 10)
+	if (&BigInteger{Value: huge3}).HashKey() != (&Float{Value: 1e19}).HashKey() {
+		t.Errorf("big integers should hash like the equal whole float!")
+	}
+
+	if (&BigInteger{Value: huge3}).HashKey() == (&Float{Value: math.Inf(1)}).HashKey() {
+		t.Errorf("infinity should not hash like a big integer!")
+	}
+}
+
+func TestErrorStackTrace(t *testing.T) {
+	at := func(line, column int) token.Position {
+		return token.Position{Filename: "main.mk", Line: line, Column: column}
+	}
+	err := &Error{
+		Message: "division by zero",
+		Stack: []StackFrame{
+			{Function: "divide", Position: at(2, 5)},
+			{Function: "loop", Position: at(4, 10)},
+			{Function: "loop", Position: at(4, 10)},
+			{Function: "loop", Position: at(6, 1)},
+		},
+	}
+
+	expected := "ERROR: division by zero\n" +
+		"\tat divide (main.mk:2:5)\n" +
+		"\tat loop (main.mk:4:10)\n" +
+		"\t[previous frame repeated 1 more times]\n" +
+		"\tat loop (main.mk:6:1)"
+	if err.StackTrace() != expected {
+		t.Errorf("wrong stack trace, expected=%q, got=%q", expected, err.StackTrace())
+	}
+
+	topLevel := &Error{Message: "identifier not found: x"}
+	if topLevel.StackTrace() != topLevel.Inspect() {
+		t.Errorf("top level error should render without frames, got=%q", topLevel.StackTrace())
+	}
+}
@@ -0,0 +1,128 @@
+package parser
+
+import (
+	"fmt"
+	"monkey/ast"
+	"monkey/token"
+)
+
+// maxErrors caps the number of diagnostics reported for a single program.
+const maxErrors = 10
+
+// ParseError is a single diagnostic produced while parsing. Start and End
+// delimit the offending token so callers can point at the exact spot.
+type ParseError struct {
+	Message string
+	Start   token.Position
+	End     token.Position
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Start, e.Message)
+}
+
+// errorAt reports an error at tok. Only the first error of a statement is
+// kept, the ones after it are most likely caused by the first one.
+func (p *Parser) errorAt(tok token.Token, format string, a ...interface{}) {
+	if p.panicking {
+		return
+	}
+	p.panicking = true
+	p.panicPos = tok.Start
+	p.panicLiterals = 0
+	for i := len(p.braces) - 1; i >= 0 && !p.braces[i]; i-- {
+		p.panicLiterals++
+	}
+
+	if len(p.errors) >= maxErrors {
+		if len(p.errors) == maxErrors {
+			p.errors = append(p.errors, &ParseError{
+				Message: "too many errors",
+				Start:   tok.Start,
+				End:     tok.End,
+			})
+		}
+		return
+	}
+
+	err := &ParseError{
+		Message: fmt.Sprintf(format, a...),
+		Start:   tok.Start,
+		End:     tok.End,
+	}
+	if n := len(p.errors); n > 0 && *p.errors[n-1] == *err {
+		return
+	}
+	p.errors = append(p.errors, err)
+}
+
+// statementKeywords are the tokens parsing resumes at after an error.
+var statementKeywords = map[token.TokenType]bool{
+	token.LET:      true,
+	token.RETURN:   true,
+	token.IF:       true,
+	token.WHILE:    true,
+	token.FOR:      true,
+	token.BREAK:    true,
+	token.CONTINUE: true,
+}
+
+// parseStatementWithRecovery parses a statement and, if it reported an
+// error, skips the rest of it and returns an ast.BadStatement in its place.
+// closed reports that the current token is the brace closing the enclosing
+// block.
+func (p *Parser) parseStatementWithRecovery() (stmt ast.Statement, closed bool) {
+	if p.panicking {
+		// an enclosing statement is already broken and will be discarded
+		return p.parseStatement(), false
+	}
+
+	start := p.curToken
+	stmt = p.parseStatement()
+	if !p.panicking {
+		return stmt, false
+	}
+
+	closed = p.synchronize()
+	p.panicking = false
+
+	return &ast.BadStatement{Token: start, End: p.curToken.End}, closed
+}
+
+// synchronize advances to the last token of a broken statement: its
+// semicolon, or the token before the next statement keyword or closing
+// brace. Braces opened within the statement are skipped as a whole,
+// including those of object literals the error was reported in. It returns
+// true if it stopped on the closing brace the error was reported at instead,
+// when that brace closes the enclosing block. A brace at the top level is
+// skipped with the statement.
+func (p *Parser) synchronize() bool {
+	depth, literals := 0, p.panicLiterals
+	for !p.curTokenIs(token.EOF) {
+		switch p.curToken.Type {
+		case token.LBRACE:
+			depth++
+		case token.RBRACE:
+			if depth == 0 && literals == 0 && p.curToken.Start == p.panicPos && len(p.braces) > 0 {
+				return true
+			}
+			if depth > 0 {
+				depth--
+			} else if literals > 0 {
+				literals--
+			}
+		case token.SEMICOLON:
+			// an object literal cannot contain one, so it was left open
+			if depth == 0 {
+				return false
+			}
+		}
+
+		if depth == 0 && literals == 0 && (p.peekTokenIs(token.RBRACE) ||
+			p.peekTokenIs(token.EOF) || statementKeywords[p.peekToken.Type]) {
+			return false
+		}
+		p.nextToken()
+	}
+	return false
+}
@@ -1,7 +1,6 @@
 package parser
 
 import (
-	"strconv"
 	"fmt"
 	"monkey/ast"
 	"monkey/lexer"
@@ -20,7 +19,7 @@ func TestLetStatements(t *testing.T) {
 	}
 
 	for _, tt := range tests {
-		l := lexer.New(tt.input)
+		l := lexer.New("", tt.input)
 		p := New(l)
 		program := p.ParseProgram()
 		checkParserErrors(t, p)
@@ -54,7 +53,7 @@ func TestReturnStatements(t *testing.T) {
 	}
 
 	for _, tt := range tests {
-		l := lexer.New(tt.input)
+		l := lexer.New("", tt.input)
 		p := New(l)
 		program := p.ParseProgram()
 		checkParserErrors(t, p)
@@ -107,7 +106,7 @@ func testLetStatements(t *testing.T, s ast.Statement, name string) bool {
 func TestIntegerLiteralExpression(t *testing.T) {
 	input := "5;"
 
-	l := lexer.New(input)
+	l := lexer.New("", input)
 	p := New(l)
 	program := p.ParseProgram()
 	checkParserErrors(t, p)
@@ -136,6 +135,149 @@ func TestIntegerLiteralExpression(t *testing.T) {
 
 }
 
+func TestIntegerLiteralNotations(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"0xFF", 255},
+		{"0o755", 493},
+		{"0b1010", 10},
+		{"1_000_000", 1000000},
+		{"0x_7f", 127},
+		{"0", 0},
+		{"0o10", 8},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New("", tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		literal, ok := stmt.Expression.(*ast.IntegerLiteral)
+		if !ok {
+			t.Fatalf("exp not *ast.IntegerLiteral, got=%T", stmt.Expression)
+		}
+		if literal.Value != tt.expected {
+			t.Errorf("literal.Value not %d. got=%d", tt.expected, literal.Value)
+		}
+	}
+}
+
+func TestBigIntegerLiteral(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"5n", "5"},
+		{"123456789012345678901234567890n", "123456789012345678901234567890"},
+		{"0xffff_ffff_ffff_ffff_ffffn", "1208925819614629174706175"},
+		{"0b1n", "1"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New("", tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		literal, ok := stmt.Expression.(*ast.BigIntegerLiteral)
+		if !ok {
+			t.Fatalf("exp not *ast.BigIntegerLiteral, got=%T", stmt.Expression)
+		}
+		if literal.Value.String() != tt.expected {
+			t.Errorf("literal.Value not %s. got=%s", tt.expected, literal.Value)
+		}
+		if literal.TokenLiteral() != tt.input {
+			t.Errorf("literal.TokenLiteral not %s. got=%s", tt.input, literal.TokenLiteral())
+		}
+	}
+}
+
+func TestNumberLiteralErrors(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedMessage string
+		expectedColumn  int
+	}{
+		{"1 + 9223372036854775808", "integer literal 9223372036854775808 overflows int64", 5},
+		{"x * 0xFFFFFFFFFFFFFFFFF", "integer literal 0xFFFFFFFFFFFFFFFFF overflows int64", 5},
+		{"0b102", "invalid integer literal \"0b102\"", 1},
+		{"1__0", "invalid integer literal \"1__0\"", 1},
+		{"0x", "invalid integer literal \"0x\"", 1},
+		{"x + 010", "leading zeros in integer literal \"010\", use 0o for octal", 5},
+		{"08", "leading zeros in integer literal \"08\", use 0o for octal", 1},
+		{"0_7", "leading zeros in integer literal \"0_7\", use 0o for octal", 1},
+		{"010n", "leading zeros in integer literal \"010n\", use 0o for octal", 1},
+		{"01.5", "leading zeros in float literal \"01.5\"", 1},
+		{"x - 00e3", "leading zeros in float literal \"00e3\"", 5},
+		{"0.1_5", "digit separator after the integer part of float literal \"0.1_5\"", 1},
+		{"1e1_0", "digit separator after the integer part of float literal \"1e1_0\"", 1},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New("", tt.input)
+		p := New(l)
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) != 1 {
+			t.Errorf("expected 1 parser error for %q, got=%d", tt.input, len(errors))
+			continue
+		}
+		if errors[0].Message != tt.expectedMessage {
+			t.Errorf("wrong error message, expected=%q, got=%q",
+				tt.expectedMessage, errors[0].Message)
+		}
+		if errors[0].Start.Column != tt.expectedColumn {
+			t.Errorf("wrong error column for %q, expected=%d, got=%d",
+				tt.input, tt.expectedColumn, errors[0].Start.Column)
+		}
+	}
+}
+
+func TestFloatLiteralExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected float64
+	}{
+		{"3.14;", 3.14},
+		{"1e3", 1000},
+		{"2.5e-1", 0.25},
+		{"0.5", 0.5},
+		{"0e3", 0},
+		{"1_000.25", 1000.25},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New("", tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("program has not enough statements, got=%d",
+				len(program.Statements))
+		}
+		stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+		if !ok {
+			t.Fatalf("program.Statements[0] is not ast.ExpressionStatement, got=%T",
+				program.Statements[0])
+		}
+
+		literal, ok := stmt.Expression.(*ast.FloatLiteral)
+		if !ok {
+			t.Fatalf("exp not *ast.FloatLiteral, got=%T", stmt.Expression)
+		}
+		if literal.Value != tt.expected {
+			t.Errorf("literal.Value not %g. got=%g", tt.expected, literal.Value)
+		}
+	}
+}
+
 func TestParsingPrefixExpressions(t *testing.T) {
 	prefixTests := []struct {
 		input    string
@@ -146,10 +288,11 @@ func TestParsingPrefixExpressions(t *testing.T) {
 		{"-15;", "-", 15},
 		{"!true", "!", true},
 		{"!false", "!", false},
+		{"~5;", "~", 5},
 	}
 
 	for _, tt := range prefixTests {
-		l := lexer.New(tt.input)
+		l := lexer.New("", tt.input)
 		p := New(l)
 		program := p.ParseProgram()
 		checkParserErrors(t, p)
@@ -194,13 +337,25 @@ func TestParsingInfixExpressions(t *testing.T) {
 		{"5 < 5;", 5, "<", 5},
 		{"5 == 5;", 5, "==", 5},
 		{"5 != 5;", 5, "!=", 5},
+		{"5 <= 5;", 5, "<=", 5},
+		{"5 >= 5;", 5, ">=", 5},
+		{"5 % 5;", 5, "%", 5},
+		{"5 ** 5;", 5, "**", 5},
+		{"5 & 5;", 5, "&", 5},
+		{"5 | 5;", 5, "|", 5},
+		{"5 ^ 5;", 5, "^", 5},
+		{"5 << 5;", 5, "<<", 5},
+		{"5 >> 5;", 5, ">>", 5},
+		{"a in b;", "a", "in", "b"},
+		{"true && false", true, "&&", false},
+		{"false || true", false, "||", true},
 		{"true == true", true, "==", true},
 		{"true != false", true, "!=", false},
 		{"false == false", false, "==", false},
 	}
 
 	for _, tt := range infixTests {
-		l := lexer.New(tt.input)
+		l := lexer.New("", tt.input)
 		p := New(l)
 		program := p.ParseProgram()
 		checkParserErrors(t, p)
@@ -341,10 +496,74 @@ func TestOperatorPrecedenceParsing(t *testing.T) {
 			"add(a * b[2], b[1], 2 * [1, 2][1])",
 			"add((a * (b[2])), (b[1]), (2 * ([1, 2][1])))",
 		},
+		{
+			"a <= b == c >= d",
+			"((a <= b) == (c >= d))",
+		},
+		{
+			"a || b && c",
+			"(a || (b && c))",
+		},
+		{
+			"a && b || c && d",
+			"((a && b) || (c && d))",
+		},
+		{
+			"a == b && c < d || !e",
+			"(((a == b) && (c < d)) || (!e))",
+		},
+		{
+			"x = a || b",
+			"(x = (a || b))",
+		},
+		{
+			"a + b % c ** d",
+			"(a + (b % (c ** d)))",
+		},
+		{
+			"a ** b ** c",
+			"(a ** (b ** c))",
+		},
+		{
+			"-a ** b",
+			"(-(a ** b))",
+		},
+		{
+			"a ** -b",
+			"(a ** (-b))",
+		},
+		{
+			"~a & b",
+			"((~a) & b)",
+		},
+		{
+			"a | b ^ c & d",
+			"(a | (b ^ (c & d)))",
+		},
+		{
+			"a & b == c",
+			"(a & (b == c))",
+		},
+		{
+			"a << b + c < d >> e",
+			"((a << (b + c)) < (d >> e))",
+		},
+		{
+			"a || b | c && d",
+			"(a || ((b | c) && d))",
+		},
+		{
+			"a + b in c == d",
+			"(((a + b) in c) == d)",
+		},
+		{
+			"!(a in b) && c",
+			"((!(a in b)) && c)",
+		},
 	}
 
 	for _, tt := range tests {
-		l := lexer.New(tt.input)
+		l := lexer.New("", tt.input)
 		p := New(l)
 		program := p.ParseProgram()
 		checkParserErrors(t, p)
@@ -359,7 +578,7 @@ func TestOperatorPrecedenceParsing(t *testing.T) {
 func TestIndentifierExpression(t *testing.T) {
 	input := "foobar;"
 
-	l := lexer.New(input)
+	l := lexer.New("", input)
 	p := New(l)
 	program := p.ParseProgram()
 	checkParserErrors(t, p)
@@ -397,7 +616,7 @@ func TestBooleanExpression(t *testing.T) {
 	}
 
 	for _, tt := range tests {
-		l := lexer.New(tt.input)
+		l := lexer.New("", tt.input)
 		p := New(l)
 		program := p.ParseProgram()
 		checkParserErrors(t, p)
@@ -426,7 +645,7 @@ func TestBooleanExpression(t *testing.T) {
 func TestIfExpression(t *testing.T) {
 	input := `if (x < y) { x }`
 
-	l := lexer.New(input)
+	l := lexer.New("", input)
 	p := New(l)
 	program := p.ParseProgram()
 	checkParserErrors(t, p)
@@ -477,7 +696,7 @@ func TestIfExpression(t *testing.T) {
 func TestIfElseExpression(t *testing.T) {
 	input := `if (x < y) { x } else { y }`
 
-	l := lexer.New(input)
+	l := lexer.New("", input)
 	p := New(l)
 	program := p.ParseProgram()
 	checkParserErrors(t, p)
@@ -536,7 +755,7 @@ func TestIfElseExpression(t *testing.T) {
 func TestFunctionLiteralParsing(t *testing.T) {
 	input := `fn(x, y) { x + y; }`
 
-	l := lexer.New(input)
+	l := lexer.New("", input)
 	p := New(l)
 	program := p.ParseProgram()
 	checkParserErrors(t, p)
@@ -592,7 +811,7 @@ func TestFunctionParameterParsing(t *testing.T) {
 	}
 
 	for _, tt := range tests {
-		l := lexer.New(tt.input)
+		l := lexer.New("", tt.input)
 		p := New(l)
 		program := p.ParseProgram()
 		checkParserErrors(t, p)
@@ -614,7 +833,7 @@ func TestFunctionParameterParsing(t *testing.T) {
 func TestCallExpressionParsing(t *testing.T) {
 	input := `add(1, 2 * 3, 4 + 5);`
 
-	l := lexer.New(input)
+	l := lexer.New("", input)
 	p := New(l)
 	program := p.ParseProgram()
 	checkParserErrors(t, p)
@@ -652,7 +871,7 @@ func TestCallExpressionParsing(t *testing.T) {
 func TestStringLiteralExpression(t *testing.T) {
 	input := `"hello world!"`
 
-	l := lexer.New(input)
+	l := lexer.New("", input)
 	p := New(l)
 	program := p.ParseProgram()
 	checkParserErrors(t, p)
@@ -681,7 +900,7 @@ func TestStringLiteralExpression(t *testing.T) {
 func TestParsingArrayLiterals(t *testing.T) {
 	input := `[1, 2, 3]`
 
-	l := lexer.New(input)
+	l := lexer.New("", input)
 	p := New(l)
 	program := p.ParseProgram()
 	checkParserErrors(t, p)
@@ -708,7 +927,7 @@ func TestParsingArrayLiterals(t *testing.T) {
 func TestParsingIndexExpressions(t *testing.T) {
 	input := `someArray[5 - 4]`
 
-	l := lexer.New(input)
+	l := lexer.New("", input)
 	p := New(l)
 	program := p.ParseProgram()
 	checkParserErrors(t, p)
@@ -729,6 +948,34 @@ func TestParsingIndexExpressions(t *testing.T) {
 	}
 }
 
+func TestParsingSliceExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"a[1:2]", "(a[1:2])"},
+		{"a[1:]", "(a[1:])"},
+		{"a[:2]", "(a[:2])"},
+		{"a[:]", "(a[:])"},
+		{"a[-1:b + 1]", "(a[(-1):(b + 1)])"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New("", tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		if _, ok := stmt.Expression.(*ast.SliceExpression); !ok {
+			t.Errorf("expression is not ast.SliceExpression, got=%T", stmt.Expression)
+		}
+		if program.String() != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, program.String())
+		}
+	}
+}
+
 func TestParsingObjectLiteralKeys(t *testing.T) {
 	input := `{"a": 1, "b": 2, "c": 3}`
 	expected := map[string]int64{
@@ -737,7 +984,7 @@ func TestParsingObjectLiteralKeys(t *testing.T) {
 		"c": 3,
 	}
 
-	l := lexer.New(input)
+	l := lexer.New("", input)
 	p := New(l)
 	program := p.ParseProgram()
 	checkParserErrors(t, p)
@@ -767,7 +1014,7 @@ func TestParsingObjectLiteralKeys(t *testing.T) {
 func TestParsingEmptyObjectLiteral(t *testing.T) {
 	input := `{}`
 
-	l := lexer.New(input)
+	l := lexer.New("", input)
 	p := New(l)
 
 	program := p.ParseProgram()
@@ -787,7 +1034,7 @@ func TestParsingEmptyObjectLiteral(t *testing.T) {
 func TestParsingObjectLiteralIntKeys(t *testing.T) {
 	input := `{ 1: 3, 2: 4 }`
 
-	l := lexer.New(input)
+	l := lexer.New("", input)
 	p := New(l)
 
 	program := p.ParseProgram()
@@ -809,13 +1056,15 @@ func TestParsingObjectLiteralIntKeys(t *testing.T) {
 			t.Errorf("key is not integer, got=%T", k)
 			return
 		}
+
+		testIntegerLiteral(t, v, integer.Value+2)
 	}
 }
 
 func TestParsingObjectLiteralBoolKeys(t *testing.T) {
 	input := `{ true: 1, false: 2 }`
 
-	l := lexer.New(input)
+	l := lexer.New("", input)
 	p := New(l)
 
 	program := p.ParseProgram()
@@ -831,12 +1080,16 @@ func TestParsingObjectLiteralBoolKeys(t *testing.T) {
 		t.Errorf("obj should have 2 key-value pairs, got=%d of them", len(obj.Pairs))
 	}
 
+	expected := map[bool]int64{true: 1, false: 2}
+
 	for k, v := range obj.Pairs {
 		boolean, ok := k.(*ast.Boolean)
 		if !ok {
 			t.Errorf("key is not boolean, got=%T", k)
 			return
 		}
+
+		testIntegerLiteral(t, v, expected[boolean.Value])
 	}
 }
 
@@ -857,7 +1110,7 @@ func TestParsingObjectLiteralExpressionVals(t *testing.T) {
 		},
 	}
 	
-	l := lexer.New(input)
+	l := lexer.New("", input)
 	p := New(l)
 
 	program := p.ParseProgram()
@@ -1005,8 +1258,351 @@ func checkParserErrors(t *testing.T, p *Parser) {
 	}
 
 	t.Errorf("parser has %d errors", len(errors))
-	for _, msg := range errors {
-		t.Errorf("parser error: %q", msg)
+	for _, err := range errors {
+		t.Errorf("parser error: %q", err.Error())
 	}
 	t.FailNow()
 }
+
+func TestParserErrorPositions(t *testing.T) {
+	input := "let x = 5;\nlet = 10;"
+
+	l := lexer.New("main.mk", input)
+	p := New(l)
+	p.ParseProgram()
+
+	errors := p.Errors()
+	if len(errors) == 0 {
+		t.Fatalf("expected parser errors, got none")
+	}
+
+	err := errors[0]
+	if err.Message != "expected next token to be IDENT, got = instead" {
+		t.Errorf("wrong error message, got=%q", err.Message)
+	}
+	if err.Start.Line != 2 || err.Start.Column != 5 {
+		t.Errorf("wrong error start, expected=2:5, got=%s", err.Start)
+	}
+	if err.End.Line != 2 || err.End.Column != 6 {
+		t.Errorf("wrong error end, expected=2:6, got=%s", err.End)
+	}
+	if err.Error() != "main.mk:2:5: expected next token to be IDENT, got = instead" {
+		t.Errorf("wrong error string, got=%q", err.Error())
+	}
+}
+
+func TestUnterminatedStringError(t *testing.T) {
+	input := "let a = 1;\nlet s = \"oops;"
+
+	l := lexer.New("", input)
+	p := New(l)
+	p.ParseProgram()
+
+	errors := p.Errors()
+	if len(errors) == 0 {
+		t.Fatalf("expected parser errors, got none")
+	}
+
+	if errors[0].Message != "unterminated string literal" {
+		t.Errorf("wrong error message, got=%q", errors[0].Message)
+	}
+	if errors[0].Start.Line != 2 || errors[0].Start.Column != 9 {
+		t.Errorf("wrong error position, expected=2:9, got=%s", errors[0].Start)
+	}
+}
+
+func TestNodePositions(t *testing.T) {
+	input := "let a = 1;\n  add(a, 2) * b[0];"
+
+	l := lexer.New("", input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 2 {
+		t.Fatalf("program.Statements does not contain 2 statements, got=%d",
+			len(program.Statements))
+	}
+
+	stmt := program.Statements[1].(*ast.ExpressionStatement)
+	infix, ok := stmt.Expression.(*ast.InfixExpression)
+	if !ok {
+		t.Fatalf("exp not *ast.InfixExpression, got=%T", stmt.Expression)
+	}
+
+	tests := []struct {
+		node   ast.Node
+		line   int
+		column int
+	}{
+		{program, 1, 1},
+		{infix, 2, 3},
+		{infix.Left, 2, 3},
+		{infix.Right, 2, 15},
+		{infix.Right.(*ast.IndexExpression).Index, 2, 17},
+	}
+
+	for i, tt := range tests {
+		pos := tt.node.Pos()
+		if pos.Line != tt.line || pos.Column != tt.column {
+			t.Errorf("tests[%d] - wrong position for %q, expected=%d:%d, got=%s",
+				i, tt.node.String(), tt.line, tt.column, pos)
+		}
+	}
+}
+
+func TestAssignExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"x = 5;", "(x = 5)"},
+		{"x += 1 + 2;", "(x += (1 + 2))"},
+		{"x -= y * 2;", "(x -= (y * 2))"},
+		{"x *= 2; x /= 4;", "(x *= 2)(x /= 4)"},
+		{"a = b = c;", "(a = (b = c))"},
+		{"arr[1] = 5;", "((arr[1]) = 5)"},
+		{"obj[\"k\"] += f(1);", "((obj[k]) += f(1))"},
+		{"let a = b = 2;", "let a = (b = 2);"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New("", tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if program.String() != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, program.String())
+		}
+	}
+}
+
+func TestInvalidAssignTarget(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedMessage string
+	}{
+		{"5 = 3;", "cannot assign to 5"},
+		{"f() += 1;", "cannot assign to f()"},
+		{"(a + b) = 1;", "cannot assign to (a + b)"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New("", tt.input)
+		p := New(l)
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) != 1 {
+			t.Errorf("expected 1 parser error for %q, got=%d", tt.input, len(errors))
+			continue
+		}
+		if errors[0].Message != tt.expectedMessage {
+			t.Errorf("wrong error message, expected=%q, got=%q",
+				tt.expectedMessage, errors[0].Message)
+		}
+	}
+}
+
+func TestErrorRecovery(t *testing.T) {
+	tests := []struct {
+		input              string
+		expectedErrors     []string
+		expectedStatements []string
+	}{
+		{
+			"let = 5; let y = 10;",
+			[]string{"1:5: expected next token to be IDENT, got = instead"},
+			[]string{"<bad statement>", "let y = 10;"},
+		},
+		{
+			"let x = 5 * * 2; x",
+			[]string{"1:13: no prefix parse function for * found"},
+			[]string{"<bad statement>", "x"},
+		},
+		{
+			"if (x { let y = 1; y } let z = 2;",
+			[]string{"1:7: expected next token to be ), got { instead"},
+			[]string{"<bad statement>", "let z = 2;"},
+		},
+		{
+			"let f = fn() { let a = ; a }; f()",
+			[]string{"1:24: no prefix parse function for ; found"},
+			[]string{"let f = fn() <bad statement>a;", "f()"},
+		},
+		{
+			"let f = fn() { 1 + }; f()",
+			[]string{"1:20: no prefix parse function for } found"},
+			[]string{"let f = fn() <bad statement>;", "f()"},
+		},
+		{
+			"} let a = 1;",
+			[]string{"1:1: no prefix parse function for } found"},
+			[]string{"<bad statement>", "let a = 1;"},
+		},
+		{
+			"let a = {1: }; a",
+			[]string{"1:13: no prefix parse function for } found"},
+			[]string{"<bad statement>", "a"},
+		},
+		{
+			"let f = fn() { let a = {1: }; a }; f()",
+			[]string{"1:28: no prefix parse function for } found"},
+			[]string{"let f = fn() <bad statement>a;", "f()"},
+		},
+		{
+			`let o = {"a" 1}; o`,
+			[]string{"1:14: expected next token to be :, got INT instead"},
+			[]string{"<bad statement>", "o"},
+		},
+		{
+			`let f = fn() { let o = {"a" 1}; o }; f()`,
+			[]string{"1:29: expected next token to be :, got INT instead"},
+			[]string{"let f = fn() <bad statement>o;", "f()"},
+		},
+		{
+			`let o = {"a": {"b" 1}, "c": 2}; o`,
+			[]string{"1:20: expected next token to be :, got INT instead"},
+			[]string{"<bad statement>", "o"},
+		},
+		{
+			`let o = {"a": 1; let b = 2;`,
+			[]string{"1:16: expected next token to be ,, got ; instead"},
+			[]string{"<bad statement>", "let b = 2;"},
+		},
+		{
+			"let a = [1, 2; let b = 3;",
+			[]string{"1:14: expected next token to be ], got ; instead"},
+			[]string{"<bad statement>", "let b = 3;"},
+		},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New("", tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+
+		errors := []string{}
+		for _, err := range p.Errors() {
+			errors = append(errors, err.Error())
+		}
+		if fmt.Sprint(errors) != fmt.Sprint(tt.expectedErrors) {
+			t.Errorf("wrong errors for %q, expected=%q, got=%q",
+				tt.input, tt.expectedErrors, errors)
+		}
+
+		statements := []string{}
+		for _, stmt := range program.Statements {
+			statements = append(statements, stmt.String())
+		}
+		if fmt.Sprint(statements) != fmt.Sprint(tt.expectedStatements) {
+			t.Errorf("wrong statements for %q, expected=%q, got=%q",
+				tt.input, tt.expectedStatements, statements)
+		}
+	}
+}
+
+func TestErrorLimit(t *testing.T) {
+	input := ""
+	for i := 0; i < 20; i++ {
+		input += "let = 1;\n"
+	}
+
+	l := lexer.New("", input)
+	p := New(l)
+	program := p.ParseProgram()
+
+	errors := p.Errors()
+	if len(errors) != maxErrors+1 {
+		t.Fatalf("expected %d errors, got=%d", maxErrors+1, len(errors))
+	}
+	if errors[maxErrors].Message != "too many errors" {
+		t.Errorf("last error should be 'too many errors', got=%q", errors[maxErrors].Message)
+	}
+	if len(program.Statements) != 20 {
+		t.Errorf("expected 20 bad statements, got=%d", len(program.Statements))
+	}
+}
+
+func TestLoopStatements(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"while (x < 10) { x += 1; }", "while ((x < 10)) (x += 1)"},
+		{"for (let i = 0; i < n; i += 1) { f(i); }", "for (let i = 0; (i < n); (i += 1)) f(i)"},
+		{"for (i = 0; i < n; i += 1) { }", "for ((i = 0); (i < n); (i += 1)) "},
+		{"for (;;) { break; }", "for (; ; ) break;"},
+		{"for (x in [1, 2]) { continue; }", "for (x in [1, 2]) continue;"},
+		{"while (x) { y };", "while (x) y"},
+		{"for (;;) { };", "for (; ; ) "},
+		{"for (x in y) { };", "for (x in y) "},
+		{"while (true) { if (x) { break } else { continue } }", "while (true) ifx break;else continue;"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New("", tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain 1 statement for %q, got=%d",
+				tt.input, len(program.Statements))
+		}
+		if program.String() != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, program.String())
+		}
+	}
+}
+
+func TestForInStatement(t *testing.T) {
+	input := `for (item in items) { item; }`
+
+	l := lexer.New("", input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt, ok := program.Statements[0].(*ast.ForInStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.ForInStatement. got=%T", program.Statements[0])
+	}
+	if !testIdentifier(t, stmt.Variable, "item") {
+		return
+	}
+	if !testIdentifier(t, stmt.Iterable, "items") {
+		return
+	}
+	if len(stmt.Body.Statements) != 1 {
+		t.Errorf("body is not 1 statement. got=%d", len(stmt.Body.Statements))
+	}
+}
+
+func TestLoopControlOutsideLoop(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedMessage string
+	}{
+		{"break;", "break outside of a loop"},
+		{"if (x) { continue; }", "continue outside of a loop"},
+		{"while (x) { fn() { break; }; }", "break outside of a loop"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New("", tt.input)
+		p := New(l)
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) != 1 {
+			t.Errorf("expected 1 parser error for %q, got=%d", tt.input, len(errors))
+			continue
+		}
+		if errors[0].Message != tt.expectedMessage {
+			t.Errorf("wrong error message, expected=%q, got=%q",
+				tt.expectedMessage, errors[0].Message)
+		}
+	}
+}
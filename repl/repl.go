@@ -8,40 +8,63 @@ import (
 	"monkey/lexer"
 	"monkey/object"
 	"monkey/parser"
+	"strings"
 )
 
 const PROMOT = ">> "
 
 func Start(in io.Reader, out io.Writer) {
-	scanner := bufio.NewScanner(in)
-	env := object.NewEnvironment()
+	reader := bufio.NewReader(in)
+	env := evaluator.NewEnvironment(evaluator.Config{Output: out})
 
 	for {
 		fmt.Printf(PROMOT)
-		scanned := scanner.Scan()
-		if !scanned {
+		line, err := reader.ReadString('\n')
+		if line == "" && err != nil {
 			return
 		}
-		line := scanner.Text()
-		l := lexer.New(line)
-		p := parser.New(l)
-
-		program := p.ParseProgram()
-		if len(p.Errors()) != 0 {
-			printParserErrors(out, p.Errors())
-			continue
-		}
 
-		evaluated := evaluator.Eval(program, env)
-		if evaluated != nil {
+		l := lexer.NewReader("", strings.NewReader(line))
+		evaluated, ok := evaluate(l, env, out)
+		if ok && evaluated != nil {
 			io.WriteString(out, evaluated.Inspect())
 			io.WriteString(out, "\n")
 		}
 	}
 }
 
-func printParserErrors(out io.Writer, errors []string) {
-	for _, msg := range errors {
-		io.WriteString(out, "\t"+msg+"\n")
+// Run parses and evaluates a whole program read incrementally from in.
+// The program prints to out while parser and runtime errors are reported
+// to errOut. It returns false if the program failed.
+func Run(filename string, in io.Reader, out, errOut io.Writer) bool {
+	env := evaluator.NewEnvironment(evaluator.Config{Output: out})
+	_, ok := evaluate(lexer.NewReader(filename, in), env, errOut)
+	return ok
+}
+
+// evaluate parses the program read from l and evaluates it in env. Parser
+// errors and the stack trace of a runtime error are written to out, in which
+// case ok is false.
+func evaluate(l *lexer.Lexer, env *object.Environment, out io.Writer) (evaluated object.Object, ok bool) {
+	p := parser.New(l)
+
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		printParserErrors(out, p.Errors())
+		return nil, false
+	}
+
+	evaluated = evaluator.Eval(program, env)
+	if errObj, isErr := evaluated.(*object.Error); isErr {
+		io.WriteString(out, errObj.StackTrace())
+		io.WriteString(out, "\n")
+		return evaluated, false
+	}
+	return evaluated, true
+}
+
+func printParserErrors(out io.Writer, errors []*parser.ParseError) {
+	for _, err := range errors {
+		io.WriteString(out, "\t"+err.Error()+"\n")
 	}
 }
@@ -1,55 +1,112 @@
 package token
 
+import "fmt"
+
 const (
-	ILLEGAL   = "ILLEGAL"
-	EOF       = "EOF"
-	IDENT     = "IDENT"
-	INT       = "INT"
-	ASSIGN    = "="
-	PLUS      = "+"
-	MINUS     = "-"
-	BANG      = "!"
-	ASTERISK  = "*"
-	SLASH     = "/"
-	LT        = "<"
-	GT        = ">"
-	LBRACE    = "{"
-	RBRACE    = "}"
-	LPAREN    = "("
-	RPAREN    = ")"
-	COMMA     = ","
-	SEMICOLON = ";"
-	EQ        = "=="
-	NOT_EQ    = "!="
-	FUNCTION  = "FUNCTION"
-	LET       = "LET"
-	TRUE      = "TRUE"
-	FALSE     = "FALSE"
-	IF        = "IF"
-	ELSE      = "ELSE"
-	RETURN    = "RETURN"
-	STRING    = "STRING"
-	LBRACKET  = "["
-	RBRACKET  = "]"
-	COLON			= ":"
+	ILLEGAL         = "ILLEGAL"
+	ERROR           = "ERROR" // malformed literal, Literal holds the message
+	COMMENT         = "COMMENT"
+	EOF             = "EOF"
+	IDENT           = "IDENT"
+	INT             = "INT"
+	BIGINT          = "BIGINT" // integer literal with the n suffix, 10n
+	FLOAT           = "FLOAT"
+	ASSIGN          = "="
+	PLUS_ASSIGN     = "+="
+	MINUS_ASSIGN    = "-="
+	ASTERISK_ASSIGN = "*="
+	SLASH_ASSIGN    = "/="
+	PLUS            = "+"
+	MINUS           = "-"
+	BANG            = "!"
+	ASTERISK        = "*"
+	SLASH           = "/"
+	PERCENT         = "%"
+	POWER           = "**"
+	AMPERSAND       = "&"
+	PIPE            = "|"
+	CARET           = "^"
+	TILDE           = "~"
+	SHIFT_LEFT      = "<<"
+	SHIFT_RIGHT     = ">>"
+	LT              = "<"
+	GT              = ">"
+	LT_EQ           = "<="
+	GT_EQ           = ">="
+	AND             = "&&"
+	OR              = "||"
+	LBRACE          = "{"
+	RBRACE          = "}"
+	LPAREN          = "("
+	RPAREN          = ")"
+	COMMA           = ","
+	SEMICOLON       = ";"
+	EQ              = "=="
+	NOT_EQ          = "!="
+	FUNCTION        = "FUNCTION"
+	LET             = "LET"
+	TRUE            = "TRUE"
+	FALSE           = "FALSE"
+	IF              = "IF"
+	ELSE            = "ELSE"
+	RETURN          = "RETURN"
+	WHILE           = "WHILE"
+	FOR             = "FOR"
+	IN              = "IN"
+	BREAK           = "BREAK"
+	CONTINUE        = "CONTINUE"
+	STRING          = "STRING"
+	LBRACKET        = "["
+	RBRACKET        = "]"
+	COLON           = ":"
 )
 
-// TODO: add filename and line number / column info
-
 var keywords = map[string]TokenType{
-	"fn":     FUNCTION,
-	"let":    LET,
-	"true":   TRUE,
-	"false":  FALSE,
-	"if":     IF,
-	"else":   ELSE,
-	"return": RETURN,
+	"fn":       FUNCTION,
+	"let":      LET,
+	"true":     TRUE,
+	"false":    FALSE,
+	"if":       IF,
+	"else":     ELSE,
+	"return":   RETURN,
+	"while":    WHILE,
+	"for":      FOR,
+	"in":       IN,
+	"break":    BREAK,
+	"continue": CONTINUE,
 }
 
 type TokenType string // TODO: might not need to use string, just byte enums
 type Token struct {
 	Type    TokenType
 	Literal string
+	Start   Position
+	End     Position // position just past the last character of the token
+}
+
+// Position describes a location in the source: Offset is a zero-based byte
+// offset, Line and Column are one-based and Column counts runes, not bytes.
+type Position struct {
+	Filename string
+	Offset   int
+	Line     int
+	Column   int
+}
+
+func (p Position) IsValid() bool { return p.Line > 0 }
+
+func (p Position) String() string {
+	s := p.Filename
+	if p.IsValid() {
+		if s != "" {
+			s += ":"
+		}
+		s += fmt.Sprintf("%d:%d", p.Line, p.Column)
+	}
+	if s == "" {
+		s = "-"
+	}
+	return s
 }
 
 func LookupIdent(ident string) TokenType {